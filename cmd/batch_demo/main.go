@@ -44,6 +44,7 @@ func main() {
 	if err != nil {
 		log.Fatal("创建工具失败", zap.Error(err))
 	}
+	defer webNoteTool.Close()
 
 	// 5. 显示缓存状态
 	stats := webNoteTool.GetCacheStats()
@@ -59,7 +60,10 @@ func main() {
 	folder := "Inbox"
 
 	log.Info("开始批量处理", zap.Int("url_count", len(urls)))
-	responses := webNoteTool.SaveWebNoteBatch(ctx, urls, tags, folder)
+	responses := webNoteTool.SaveWebNoteBatch(ctx, urls, tool.SaveWebNoteRequest{
+		Tags:   tags,
+		Folder: folder,
+	})
 
 	// 7. 显示结果
 	fmt.Println("\n" + "=" + string(make([]byte, 80)) + "=")
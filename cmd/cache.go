@@ -16,7 +16,7 @@ import (
 var cacheCmd = &cobra.Command{
 	Use:   "cache",
 	Short: "缓存管理",
-	Long:  `管理网页抓取缓存,包括清空缓存和查看缓存统计。`,
+	Long:  `管理网页抓取缓存,包括查看、删除、清理、导入导出缓存和查看缓存统计。`,
 }
 
 // cacheClearCmd 清空缓存命令
@@ -46,6 +46,7 @@ var cacheClearCmd = &cobra.Command{
 			fmt.Printf("❌ 创建工具失败: %v\n", err)
 			os.Exit(1)
 		}
+		defer webNoteTool.Close()
 
 		// 清空缓存
 		webNoteTool.ClearCache()
@@ -80,6 +81,7 @@ var cacheStatsCmd = &cobra.Command{
 			fmt.Printf("❌ 创建工具失败: %v\n", err)
 			os.Exit(1)
 		}
+		defer webNoteTool.Close()
 
 		// 获取缓存统计
 		stats := webNoteTool.GetCacheStats()
@@ -112,17 +114,49 @@ func printStats(stats map[string]interface{}) {
 		}
 
 		cacheSize, _ := stats["cache_size"].(int)
+		cacheBytes, _ := stats["cache_bytes"].(int64)
+		maxEntries, _ := stats["max_entries"].(int)
+		maxBytes, _ := stats["max_bytes"].(int64)
+		hits, _ := stats["hits"].(int64)
+		misses, _ := stats["misses"].(int64)
+		evictions, _ := stats["evictions"].(int64)
+		expired, _ := stats["expired"].(int64)
+		summaryCacheSize, _ := stats["summary_cache_size"].(int)
 		cacheTTL, _ := stats["cache_ttl"].(string)
 		maxConcurrency, _ := stats["max_concurrency"].(int)
 
+		hitRate := 0.0
+		if total := hits + misses; total > 0 {
+			hitRate = float64(hits) / float64(total) * 100
+		}
+
 		fmt.Printf("状态: 已启用\n")
-		fmt.Printf("缓存条目: %d\n", cacheSize)
+		fmt.Printf("缓存条目: %d / %d\n", cacheSize, maxEntries)
+		fmt.Printf("缓存大小: %s / %s\n", formatBytes(cacheBytes), formatBytes(maxBytes))
+		fmt.Printf("命中/未命中: %d / %d (命中率 %.1f%%)\n", hits, misses, hitRate)
+		fmt.Printf("LRU 淘汰: %d\n", evictions)
+		fmt.Printf("过期清理: %d\n", expired)
+		fmt.Printf("总结缓存: %d\n", summaryCacheSize)
 		fmt.Printf("缓存 TTL: %s\n", cacheTTL)
 		fmt.Printf("最大并发: %d\n", maxConcurrency)
 	}
 	fmt.Println("=" + "===========")
 }
 
+// formatBytes 格式化字节数
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 func loadConfigForCache() (*config.Config, error) {
 	if cfgFile != "" {
 		return config.Load(cfgFile)
@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/internal/scraper"
+	"github.com/fromsko/krio/internal/urlnorm"
+	"github.com/fromsko/krio/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listStatus    string
+	showRaw       bool
+	pruneExpired  bool
+	importRefresh bool
+)
+
+// cacheListCmd 列出缓存条目
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出缓存条目",
+	Long:  `列出网页缓存条目 (URL、大小、抓取时间、过期时间、状态),可按状态过滤。`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if listStatus != "" && listStatus != "fresh" && listStatus != "expired" && listStatus != "noted" {
+			fmt.Printf("❌ 未知状态: %s (可选 fresh/expired/noted)\n", listStatus)
+			os.Exit(1)
+		}
+
+		cache, _ := openPageCache()
+		defer cache.Close()
+
+		count := 0
+		fmt.Println(strings.Repeat("=", 120))
+		fmt.Printf("%-60s %-10s %-20s %-20s %s\n", "URL", "大小", "抓取时间", "过期时间", "状态")
+		fmt.Println(strings.Repeat("=", 120))
+		for _, entry := range cache.Entries() {
+			status := entryStatus(entry)
+			if listStatus == "noted" && entry.NotePath == "" {
+				continue
+			}
+			if (listStatus == "fresh" || listStatus == "expired") && status != listStatus {
+				continue
+			}
+			if entry.NotePath != "" {
+				status += ", 已生成笔记"
+			}
+
+			urlDisplay := entry.Key
+			if len(urlDisplay) > 57 {
+				urlDisplay = urlDisplay[:57] + "..."
+			}
+			fmt.Printf("%-60s %-10s %-20s %-20s %s\n",
+				urlDisplay,
+				formatBytes(entry.Size),
+				formatTime(entry.Page.FetchedAt),
+				formatTime(entry.ExpiresAt),
+				status,
+			)
+			count++
+		}
+		fmt.Println(strings.Repeat("=", 120))
+		fmt.Printf("共 %d 条\n", count)
+	},
+}
+
+// cacheShowCmd 显示缓存条目内容
+var cacheShowCmd = &cobra.Command{
+	Use:   "show <url>",
+	Short: "显示缓存内容",
+	Long:  `显示指定 URL 的缓存元数据和提取的正文。`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cache, _ := openPageCache()
+		defer cache.Close()
+
+		entry, ok := cache.Entry(urlnorm.Normalize(args[0]))
+		if !ok {
+			fmt.Printf("❌ 缓存中没有该 URL: %s\n", args[0])
+			os.Exit(1)
+		}
+
+		page := entry.Page
+		fmt.Printf("URL: %s\n", page.URL)
+		fmt.Printf("标题: %s\n", page.Title)
+		fmt.Printf("类型: %s\n", page.ContentType)
+		if page.Canonical != "" {
+			fmt.Printf("规范地址: %s\n", page.Canonical)
+		}
+		fmt.Printf("抓取时间: %s\n", formatTime(page.FetchedAt))
+		fmt.Printf("过期时间: %s (%s)\n", formatTime(entry.ExpiresAt), entryStatus(entry))
+		fmt.Printf("大小: %s\n", formatBytes(entry.Size))
+		if entry.NotePath != "" {
+			fmt.Printf("笔记: %s\n", entry.NotePath)
+		}
+		fmt.Println(strings.Repeat("-", 80))
+		if showRaw && page.RawHTML != "" {
+			fmt.Println(page.RawHTML)
+		} else {
+			fmt.Println(page.Content)
+		}
+	},
+}
+
+// cacheEvictCmd 删除缓存条目
+var cacheEvictCmd = &cobra.Command{
+	Use:   "evict <url|pattern>",
+	Short: "删除缓存条目",
+	Long: `删除指定 URL 的缓存条目。
+参数包含 * 时作为通配符匹配规范化后的 URL,例如 "https://example.com/*"。`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cache, _ := openPageCache()
+		defer cache.Close()
+
+		var removed int
+		if strings.Contains(args[0], "*") {
+			re := globToRegexp(args[0])
+			removed = cache.DeleteFunc(func(entry scraper.CacheEntry) bool {
+				return re.MatchString(entry.Key)
+			})
+		} else if cache.Delete(urlnorm.Normalize(args[0])) {
+			removed = 1
+		}
+
+		fmt.Printf("✅ 已删除 %d 条缓存\n", removed)
+	},
+}
+
+// cachePruneCmd 清理过期缓存
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "清理过期缓存",
+	Long:  `删除已过期的缓存条目 (包括仍在保留期内、可用于条件请求的条目)。`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !pruneExpired {
+			fmt.Println("❌ 请指定 --expired")
+			cmd.Help()
+			os.Exit(1)
+		}
+
+		cache, _ := openPageCache()
+		defer cache.Close()
+
+		removed := cache.Prune(0)
+		fmt.Printf("✅ 已清理 %d 条过期缓存\n", removed)
+	},
+}
+
+// cacheExportCmd 导出缓存
+var cacheExportCmd = &cobra.Command{
+	Use:   "export <file.tar.gz>",
+	Short: "导出缓存",
+	Long:  `将网页缓存和总结缓存打包为 tar.gz,便于在其他机器上离线复现。`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadCacheConfig()
+
+		file, err := os.Create(args[0])
+		if err != nil {
+			fmt.Printf("❌ 创建文件失败: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		count, err := scraper.ExportCache(scraper.CacheRoot(&cfg.Scraper), file)
+		if err != nil {
+			fmt.Printf("❌ 导出失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ 已导出 %d 个缓存文件到 %s\n", count, args[0])
+	},
+}
+
+// cacheImportCmd 导入缓存
+var cacheImportCmd = &cobra.Command{
+	Use:   "import <file.tar.gz>",
+	Short: "导入缓存",
+	Long:  `从 export 生成的 tar.gz 导入网页缓存和总结缓存,同名条目会被覆盖。`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadCacheConfig()
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Printf("❌ 打开文件失败: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		count, err := scraper.ImportCache(scraper.CacheRoot(&cfg.Scraper), file)
+		if err != nil {
+			fmt.Printf("❌ 导入失败: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 续期导入的条目,离线运行时直接命中缓存
+		if importRefresh {
+			cache, err := scraper.NewPersistentCache(scraper.PageCacheDir(&cfg.Scraper))
+			if err != nil {
+				fmt.Printf("❌ 打开缓存失败: %v\n", err)
+				os.Exit(1)
+			}
+			ttl := cfg.Scraper.CacheTTL
+			if ttl <= 0 {
+				ttl = time.Hour
+			}
+			for _, entry := range cache.Entries() {
+				cache.Refresh(entry.Key, ttl)
+			}
+			cache.Close()
+		}
+
+		fmt.Printf("✅ 已导入 %d 个缓存文件\n", count)
+	},
+}
+
+// loadCacheConfig 加载配置并初始化日志
+func loadCacheConfig() *config.Config {
+	cfg, err := loadConfigForCache()
+	if err != nil {
+		fmt.Printf("❌ 加载配置失败: %v\n", err)
+		os.Exit(1)
+	}
+	if err := logger.Init(cfg); err != nil {
+		fmt.Printf("❌ 初始化日志失败: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// openPageCache 直接打开网页缓存 (不创建 Obsidian 客户端等其他模块)
+func openPageCache() (*scraper.Cache, *config.Config) {
+	cfg := loadCacheConfig()
+
+	cache, err := scraper.NewPersistentCache(scraper.PageCacheDir(&cfg.Scraper))
+	if err != nil {
+		fmt.Printf("❌ 打开缓存失败: %v\n", err)
+		os.Exit(1)
+	}
+	return cache, cfg
+}
+
+// entryStatus 缓存条目状态
+func entryStatus(entry scraper.CacheEntry) string {
+	if entry.Expired() {
+		return "expired"
+	}
+	return "fresh"
+}
+
+// formatTime 格式化时间,零值显示为 -
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// globToRegexp 将通配符模式转换为正则 (* 匹配任意字符)
+func globToRegexp(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheShowCmd)
+	cacheCmd.AddCommand(cacheEvictCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheExportCmd)
+	cacheCmd.AddCommand(cacheImportCmd)
+
+	cacheListCmd.Flags().StringVarP(&listStatus, "status", "s", "",
+		"按状态过滤 (fresh/expired/noted)")
+	cacheShowCmd.Flags().BoolVar(&showRaw, "raw", false,
+		"显示原始 HTML (仅 HTML 页面)")
+	cachePruneCmd.Flags().BoolVar(&pruneExpired, "expired", false,
+		"删除所有已过期的条目")
+	cacheImportCmd.Flags().BoolVar(&importRefresh, "refresh", false,
+		"导入后按 cache_ttl 续期所有条目,便于离线复现")
+}
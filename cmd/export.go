@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fromsko/krio/internal/note"
+	"github.com/fromsko/krio/internal/obsidian"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportDir  string
+	exportDeck string
+)
+
+// exportCmd 导出命令
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "导出笔记数据",
+	Long:  `将笔记中的数据导出到其他工具。`,
+}
+
+// exportAnkiCmd 导出闪卡到 Anki
+var exportAnkiCmd = &cobra.Command{
+	Use:   "anki [output.tsv]",
+	Short: "导出闪卡到 Anki",
+	Long: `扫描笔记中的闪卡区块,导出为 Anki 可导入的 TSV 文件 (默认 krio-anki.tsv)。
+问答卡使用 Basic 笔记类型,填空卡使用 Cloze 笔记类型。
+卡片 ID 由来源地址和卡片正面生成,重复导入时 Anki 会更新已有卡片而不是重复添加。`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadCacheConfig()
+
+		dir := exportDir
+		if dir == "" || !filepath.IsAbs(dir) {
+			vault := obsidian.ResolveVaultPath(&cfg.ObsidianMCP)
+			if vault == "" {
+				fmt.Println("❌ 未配置 vault 路径 (obsidian_mcp.vault_path),请用 --dir 指定笔记目录")
+				os.Exit(1)
+			}
+			dir = filepath.Join(vault, dir)
+		}
+
+		cards, err := note.CollectCards(dir)
+		if err != nil {
+			fmt.Printf("❌ 读取笔记失败: %v\n", err)
+			os.Exit(1)
+		}
+		if len(cards) == 0 {
+			fmt.Printf("⚠️  %s 中没有找到闪卡 (启用 note.flashcards 或使用 run --flashcards 生成)\n", dir)
+			return
+		}
+
+		output := "krio-anki.tsv"
+		if len(args) > 0 {
+			output = args[0]
+		}
+		file, err := os.Create(output)
+		if err != nil {
+			fmt.Printf("❌ 创建文件失败: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		deck := exportDeck
+		if deck == "" {
+			deck = note.Deck(&cfg.Note.Flashcards)
+		}
+		if err := note.WriteAnkiTSV(file, cards, deck); err != nil {
+			fmt.Printf("❌ 导出失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ 已导出 %d 张闪卡到 %s (Anki: 文件 -> 导入)\n", len(cards), output)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportAnkiCmd)
+
+	exportAnkiCmd.Flags().StringVar(&exportDir, "dir", "",
+		"笔记目录 (相对路径基于 vault 根目录,默认整个 vault)")
+	exportAnkiCmd.Flags().StringVar(&exportDeck, "deck", "",
+		"Anki 卡组名 (默认使用 note.flashcards.deck)")
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fromsko/krio/internal/tool"
+)
+
+// stageLabels 处理阶段的显示文字
+var stageLabels = map[tool.Stage]string{
+	tool.StageFetching:    "抓取中",
+	tool.StageExtracting:  "提取正文",
+	tool.StageSummarizing: "总结中",
+	tool.StageSaving:      "保存笔记",
+	tool.StageDone:        "完成",
+}
+
+// 状态行显示参数
+const (
+	progressURLWidth = 60                     // URL 最多显示的字符数
+	redrawInterval   = 100 * time.Millisecond // 同一阶段内 token 数更新的最短重绘间隔
+)
+
+// progressDisplay 显示每个 URL 的实时处理状态
+// 输出为终端时在同一行刷新 (抓取中 → 提取正文 → 总结中 N tokens → 保存笔记);
+// 否则每次阶段变化输出一行,不输出 token 数的变化
+// 同时作为控制台日志的输出,在日志前后清除并重绘状态行
+type progressDisplay struct {
+	mu     sync.Mutex
+	out    io.Writer
+	tty    bool
+	total  int                   // 本次处理的 URL 数
+	done   int                   // 已处理完的 URL 数
+	stages map[string]tool.Stage // URL -> 当前阶段
+	line   string                // 当前显示的状态行,为空时没有状态行
+	drawn  time.Time             // 上次重绘时间
+}
+
+// newProgressDisplay 创建进度显示
+func newProgressDisplay(out *os.File) *progressDisplay {
+	return &progressDisplay{out: out, tty: isTerminal(out)}
+}
+
+// isTerminal 文件是否为终端
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Start 开始显示 total 个 URL 的进度
+func (d *progressDisplay) Start(total int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.total, d.done = total, 0
+	d.stages = make(map[string]tool.Stage, total)
+}
+
+// Update 更新 URL 的处理阶段 (tool.ProgressFunc)
+func (d *progressDisplay) Update(url string, stage tool.Stage, tokens int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stages == nil {
+		return
+	}
+
+	changed := d.stages[url] != stage
+	if !changed && !d.tty {
+		return
+	}
+	if changed && stage == tool.StageDone {
+		d.done++
+	}
+	d.stages[url] = stage
+
+	text := fmt.Sprintf("[%d/%d] %s %s", d.done, d.total, stageLabels[stage], shortenURL(url))
+	if stage == tool.StageSummarizing && tokens > 0 {
+		text += fmt.Sprintf(" (%d tokens)", tokens)
+	}
+
+	if !d.tty {
+		fmt.Fprintln(d.out, text)
+		return
+	}
+	if !changed && time.Since(d.drawn) < redrawInterval {
+		return
+	}
+	d.line = "⏳ " + text
+	d.redraw()
+}
+
+// Finish 清除状态行,之后的输出不再受状态行影响
+func (d *progressDisplay) Finish() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.tty && d.line != "" {
+		fmt.Fprint(d.out, "\r\033[K")
+	}
+	d.line = ""
+	d.stages = nil
+}
+
+// Write 输出日志: 先清除状态行,输出后重绘
+func (d *progressDisplay) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.tty || d.line == "" {
+		return d.out.Write(p)
+	}
+	fmt.Fprint(d.out, "\r\033[K")
+	n, err := d.out.Write(p)
+	d.redraw()
+	return n, err
+}
+
+// redraw 在当前行重绘状态行,调用方需持有锁
+func (d *progressDisplay) redraw() {
+	fmt.Fprint(d.out, "\r\033[K"+d.line)
+	d.drawn = time.Now()
+}
+
+// shortenURL 截断过长的 URL
+func shortenURL(url string) string {
+	if utf8.RuneCountInString(url) <= progressURLWidth {
+		return url
+	}
+	return string([]rune(url)[:progressURLWidth-3]) + "..."
+}
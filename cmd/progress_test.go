@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fromsko/krio/internal/tool"
+)
+
+func TestProgressDisplay_NonTTY(t *testing.T) {
+	var buf bytes.Buffer
+	d := &progressDisplay{out: &buf}
+	url := "https://go.dev/blog"
+
+	d.Update(url, tool.StageFetching, 0) // Start 之前不输出
+	d.Start(2)
+	d.Update(url, tool.StageFetching, 0)
+	d.Update(url, tool.StageSummarizing, 0)
+	d.Update(url, tool.StageSummarizing, 120) // 同一阶段的 token 变化不输出
+	d.Update(url, tool.StageDone, 0)
+	d.Write([]byte("日志\n"))
+	d.Finish()
+
+	want := "[0/2] 抓取中 https://go.dev/blog\n" +
+		"[0/2] 总结中 https://go.dev/blog\n" +
+		"[1/2] 完成 https://go.dev/blog\n" +
+		"日志\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestProgressDisplay_TTY(t *testing.T) {
+	var buf bytes.Buffer
+	d := &progressDisplay{out: &buf, tty: true}
+	url := "https://go.dev/blog"
+	const clear = "\r\033[K"
+
+	d.Start(1)
+	d.Update(url, tool.StageSummarizing, 0)
+	if got, want := buf.String(), clear+"⏳ [0/1] 总结中 https://go.dev/blog"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+
+	// 同一阶段内的 token 更新按间隔节流
+	buf.Reset()
+	d.Update(url, tool.StageSummarizing, 50)
+	if buf.Len() != 0 {
+		t.Errorf("throttled update wrote %q", buf.String())
+	}
+	d.drawn = time.Now().Add(-redrawInterval)
+	d.Update(url, tool.StageSummarizing, 120)
+	if got, want := buf.String(), clear+"⏳ [0/1] 总结中 https://go.dev/blog (120 tokens)"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	// 日志输出前清除状态行,输出后重绘
+	buf.Reset()
+	d.Write([]byte("日志\n"))
+	if got, want := buf.String(), clear+"日志\n"+clear+"⏳ [0/1] 总结中 https://go.dev/blog (120 tokens)"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	d.Update(url, tool.StageDone, 0)
+	d.Finish()
+	if got := buf.String(); !strings.HasSuffix(got, "[1/1] 完成 https://go.dev/blog"+clear) {
+		t.Errorf("output = %q, want done line then cleared", got)
+	}
+
+	// 状态行清除后日志直接输出
+	buf.Reset()
+	d.Write([]byte("结束\n"))
+	if got := buf.String(); got != "结束\n" {
+		t.Errorf("output = %q, want %q", got, "结束\n")
+	}
+}
+
+func TestShortenURL(t *testing.T) {
+	long := "https://example.com/" + strings.Repeat("a", 100)
+	if got := shortenURL(long); len([]rune(got)) != progressURLWidth || !strings.HasSuffix(got, "...") {
+		t.Errorf("shortenURL() = %q", got)
+	}
+	if got := shortenURL("https://go.dev"); got != "https://go.dev" {
+		t.Errorf("shortenURL() = %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"github.com/spf13/cobra"
 )
@@ -21,11 +22,14 @@ var rootCmd = &cobra.Command{
   - 智能标签: AI 自动生成相关标签,便于分类和检索
   - 高性能: 支持并发处理和智能缓存
   - 易于配置: YAML 配置文件,支持环境变量覆盖`,
+	// 错误统一由 Execute 输出
+	SilenceErrors: true,
 }
 
 // Execute 执行根命令
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		fmt.Printf("❌ %v\n", err)
 		os.Exit(1)
 	}
 }
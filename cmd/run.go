@@ -4,21 +4,35 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
-	"github.com/spf13/cobra"
 	"github.com/fromsko/krio/internal/config"
 	"github.com/fromsko/krio/internal/parser"
+	"github.com/fromsko/krio/internal/policy"
+	"github.com/fromsko/krio/internal/summarizer"
 	"github.com/fromsko/krio/internal/tool"
+	"github.com/fromsko/krio/internal/urlnorm"
 	"github.com/fromsko/krio/pkg/logger"
+	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
 
 var (
-	urlFile   string
-	singleURL string
-	tags      []string
-	folder    string
+	urlFile     string
+	singleURL   string
+	tags        []string
+	folder      string
+	withImages  bool
+	archiveMode string
+	noSumCache  bool
+	force       bool
+	noteLang    string
+	style       string
+	flashcards  bool
+	modelName   string
+	maxTokens   int
+	maxCost     float64
 )
 
 // runCmd 运行命令
@@ -26,24 +40,46 @@ var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "运行网页笔记生成器",
 	Long:  `从 URL 或文件批量生成网页笔记并保存到 Obsidian。`,
-	Run: func(cmd *cobra.Command, args []string) {
+	// 出错时返回错误而不是直接退出,保证 defer 的清理 (关闭缓存、同步日志) 都会执行
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// 加载配置
 		cfg, err := loadConfig()
 		if err != nil {
-			fmt.Printf("❌ 加载配置失败: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("加载配置失败: %w", err)
+		}
+
+		// 命令行参数覆盖配置
+		if withImages {
+			cfg.Note.Images.Enabled = true
+		}
+		if archiveMode != "" {
+			cfg.Note.Archive.Enabled = true
+			cfg.Note.Archive.Mode = archiveMode
+		}
+		if noteLang != "" {
+			cfg.Note.Language = noteLang
+		}
+		if flashcards {
+			cfg.Note.Flashcards.Enabled = true
+		}
+		if maxTokens > 0 {
+			cfg.Model.Budget.MaxTokens = maxTokens
+		}
+		if maxCost > 0 {
+			cfg.Model.Budget.MaxCost = maxCost
 		}
 
-		// 验证配置
+		// 验证配置 (含命令行参数覆盖后的值)
 		if err := cfg.Validate(); err != nil {
-			fmt.Printf("❌ 配置验证失败: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("配置验证失败: %w", err)
 		}
 
-		// 初始化日志
+		// 初始化日志 (控制台日志经过进度显示输出,避免与状态行混在一起)
+		progress := newProgressDisplay(os.Stdout)
+		logger.SetConsole(progress)
 		if err := logger.Init(cfg); err != nil {
-			fmt.Printf("❌ 初始化日志失败: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("初始化日志失败: %w", err)
 		}
 		defer logger.Sync()
 
@@ -57,19 +93,25 @@ var runCmd = &cobra.Command{
 		ctx := context.Background()
 		webNoteTool, err := tool.NewSaveWebNoteTool(ctx, cfg)
 		if err != nil {
-			log.Fatal("创建工具失败", zap.Error(err))
+			log.Error("创建工具失败", zap.Error(err))
+			return fmt.Errorf("创建工具失败: %w", err)
+		}
+		defer webNoteTool.Close()
+		webNoteTool.SetProgress(progress.Update)
+
+		if style != "" && !slices.Contains(webNoteTool.Styles(), style) {
+			return fmt.Errorf("未知的总结风格: %s (可选: %s)", style, strings.Join(webNoteTool.Styles(), ", "))
 		}
 
 		// 根据参数执行
 		switch {
 		case singleURL != "":
-			runSingleURL(ctx, webNoteTool, singleURL, tags, folder)
+			return runSingleURL(ctx, webNoteTool, progress, singleURL, tags, folder)
 		case urlFile != "":
-			runFile(ctx, webNoteTool, urlFile, tags, folder)
+			return runFile(ctx, webNoteTool, progress, &cfg.Scraper.Filter, urlFile, tags, folder)
 		default:
-			fmt.Println("❌ 请指定 -u <url> 或 -r <file>")
 			cmd.Help()
-			os.Exit(1)
+			return fmt.Errorf("请指定 -u <url> 或 -r <file>")
 		}
 	},
 }
@@ -81,37 +123,61 @@ func loadConfig() (*config.Config, error) {
 	return config.LoadDefault()
 }
 
-func runSingleURL(ctx context.Context, webNoteTool *tool.SaveWebNoteTool, url string, tags []string, folder string) {
+// runSingleURL 处理单个 URL,处理失败时返回错误 (命令以非零状态退出)
+func runSingleURL(ctx context.Context, webNoteTool *tool.SaveWebNoteTool, progress *progressDisplay, url string, tags []string, folder string) error {
 	log := logger.Get()
 	log.Info("处理单个 URL", zap.String("url", url))
 
 	req := tool.SaveWebNoteRequest{
-		URL:    url,
-		Tags:   tags,
-		Folder: folder,
+		URL:            url,
+		Tags:           tags,
+		Folder:         folder,
+		NoSummaryCache: noSumCache,
+		Force:          force,
+		Style:          style,
+		Model:          modelName,
 	}
 
+	progress.Start(1)
 	resp, err := webNoteTool.SaveWebNote(ctx, req)
+	progress.Finish()
 	if err != nil {
 		log.Error("处理失败", zap.String("url", url), zap.Error(err))
-		fmt.Printf("❌ 处理失败: %v\n", err)
-		return
+		return fmt.Errorf("处理失败: %w", err)
 	}
 
-	if resp.Success {
-		fmt.Println("\n" + strings.Repeat("=", 80))
-		fmt.Println("✅ 笔记生成成功")
-		fmt.Println(strings.Repeat("=", 80))
-		fmt.Printf("标题: %s\n", resp.Title)
-		fmt.Printf("路径: %s\n", resp.FilePath)
-		if len(tags) > 0 {
-			fmt.Printf("标签: %v\n", tags)
-		}
-		fmt.Println(strings.Repeat("=", 80))
+	if resp.Status == tool.StatusBudget {
+		fmt.Printf("⏸️  %s\n", resp.Message)
+		return nil
+	}
+	if resp.Skipped {
+		fmt.Printf("⏭️  %s: %s\n", resp.Message, resp.FilePath)
+		return nil
+	}
+	if !resp.Success {
+		return fmt.Errorf("处理失败: %s", resp.Message)
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 80))
+	fmt.Println("✅ 笔记生成成功")
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Printf("标题: %s\n", resp.Title)
+	fmt.Printf("路径: %s\n", resp.FilePath)
+	if resp.Partial {
+		fmt.Println("⚠️  正文可能不完整 (需要登录或付费),笔记已标记 partial")
+	}
+	if len(tags) > 0 {
+		fmt.Printf("标签: %v\n", tags)
 	}
+	if resp.Usage != nil && resp.Usage.Calls > 0 {
+		fmt.Printf("用量: %s\n", resp.Usage)
+	}
+	fmt.Println(strings.Repeat("=", 80))
+	return nil
 }
 
-func runFile(ctx context.Context, webNoteTool *tool.SaveWebNoteTool, filePath string, tags []string, folder string) {
+// runFile 批量处理文件中的 URL,有 URL 处理失败时返回错误 (命令以非零状态退出)
+func runFile(ctx context.Context, webNoteTool *tool.SaveWebNoteTool, progress *progressDisplay, filterCfg *config.FilterConfig, filePath string, tags []string, folder string) error {
 	log := logger.Get()
 	log.Info("批量处理文件", zap.String("file", filePath))
 
@@ -119,51 +185,96 @@ func runFile(ctx context.Context, webNoteTool *tool.SaveWebNoteTool, filePath st
 	file, err := os.Open(filePath)
 	if err != nil {
 		log.Error("打开文件失败", zap.String("file", filePath), zap.Error(err))
-		fmt.Printf("❌ 打开文件失败: %v\n", err)
-		return
+		return fmt.Errorf("打开文件失败: %w", err)
 	}
 	defer file.Close()
 
-	// 根据文件扩展名选择解析器
-	p := parser.DetectFormat(filePath)
+	// 根据文件扩展名选择解析器,并按过滤规则筛选 URL
+	filter, err := policy.New(filterCfg)
+	if err != nil {
+		return fmt.Errorf("过滤规则无效: %w", err)
+	}
+	p := parser.NewFilteredParser(parser.DetectFormat(filePath), filter)
 
 	// 解析 URL
 	urls, err := p.Parse(file)
 	if err != nil {
 		log.Error("解析文件失败", zap.String("file", filePath), zap.Error(err))
-		fmt.Printf("❌ 解析文件失败: %v\n", err)
-		return
+		return fmt.Errorf("解析文件失败: %w", err)
+	}
+	for _, rejected := range p.Rejected {
+		log.Info("跳过被过滤的 URL", zap.String("url", rejected.URL), zap.Error(rejected.Reason))
+		fmt.Printf("🚫 %s: %v\n", rejected.URL, rejected.Reason)
 	}
 
 	if len(urls) == 0 {
-		fmt.Println("❌ 未找到任何 URL")
-		return
+		return fmt.Errorf("未找到任何 URL")
 	}
 
 	log.Info("找到 URL", zap.Int("count", len(urls)))
 	fmt.Printf("\n📝 开始处理 %d 个 URL...\n\n", len(urls))
 
-	// 批量处理
-	responses := webNoteTool.SaveWebNoteBatch(ctx, urls, tags, folder)
+	// 批量处理 (规范化后重复的 URL 只处理一次,不计入进度)
+	unique := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		unique[urlnorm.Normalize(u)] = true
+	}
+	progress.Start(len(unique))
+	responses := webNoteTool.SaveWebNoteBatch(ctx, urls, tool.SaveWebNoteRequest{
+		Tags:           tags,
+		Folder:         folder,
+		NoSummaryCache: noSumCache,
+		Force:          force,
+		Style:          style,
+		Model:          modelName,
+	})
+	progress.Finish()
 
 	// 显示结果
-	printResults(urls, responses)
+	if failed := printResults(urls, responses); failed > 0 {
+		return fmt.Errorf("%d 个 URL 处理失败", failed)
+	}
+	return nil
 }
 
-func printResults(urls []string, responses []tool.SaveWebNoteResponse) {
+// printResults 输出批量处理结果,返回失败 (含被拒绝) 的 URL 数
+func printResults(urls []string, responses []tool.SaveWebNoteResponse) int {
 	successCount := 0
 	failCount := 0
+	budgetCount := 0
+	dupCount := 0
+	var usage summarizer.Usage
 
 	fmt.Println(strings.Repeat("=", 100))
 	fmt.Printf("%-5s %-50s %-20s %s\n", "#", "URL", "标题", "状态")
 	fmt.Println(strings.Repeat("=", 100))
 
 	for i, resp := range responses {
+		if resp.Usage != nil {
+			usage.Add(*resp.Usage)
+		}
+
 		status := "✅ 成功"
-		if !resp.Success {
+		switch {
+		case resp.Status == tool.StatusDuplicate:
+			status = "⏭️ 重复"
+			dupCount++
+		case resp.Status == tool.StatusBudget:
+			status = "⏸️ 超出预算"
+			budgetCount++
+		case resp.Status == tool.StatusRejected:
+			status = "🚫 已拒绝"
+			failCount++
+		case !resp.Success:
 			status = "❌ 失败"
 			failCount++
-		} else {
+		case resp.Skipped:
+			status = "⏭️ 未变化"
+			successCount++
+		case resp.Partial:
+			status = "⚠️ 不完整"
+			successCount++
+		default:
 			successCount++
 		}
 
@@ -183,7 +294,18 @@ func printResults(urls []string, responses []tool.SaveWebNoteResponse) {
 	}
 
 	fmt.Println(strings.Repeat("=", 100))
-	fmt.Printf("总计: %d 成功, %d 失败\n\n", successCount, failCount)
+	fmt.Printf("总计: %d 成功, %d 失败", successCount, failCount)
+	if dupCount > 0 {
+		fmt.Printf(", %d 重复", dupCount)
+	}
+	if budgetCount > 0 {
+		fmt.Printf(", %d 因超出预算未处理", budgetCount)
+	}
+	if usage.Calls > 0 {
+		fmt.Printf("\n用量: %s", usage)
+	}
+	fmt.Print("\n\n")
+	return failCount
 }
 
 func init() {
@@ -196,4 +318,25 @@ func init() {
 		"自定义标签 (逗号分隔)")
 	runCmd.Flags().StringVarP(&folder, "folder", "f", "",
 		"目标文件夹")
+	runCmd.Flags().BoolVar(&withImages, "images", false,
+		"下载页面图片并作为附件嵌入笔记")
+	runCmd.Flags().StringVar(&archiveMode, "archive", "",
+		"存档原文 (inline: 笔记内折叠区块, file: 同级独立文件)")
+	runCmd.Flags().Lookup("archive").NoOptDefVal = "inline"
+	runCmd.Flags().BoolVar(&noSumCache, "no-summary-cache", false,
+		"忽略总结缓存,强制重新生成总结")
+	runCmd.Flags().BoolVar(&force, "force", false,
+		"重新生成已有笔记 (即使内容未变化或配置为跳过变化的页面)")
+	runCmd.Flags().StringVar(&noteLang, "lang", "",
+		"笔记语言 (zh-CN/en 等, source 表示与原文相同)")
+	runCmd.Flags().StringVar(&style, "style", "",
+		"总结风格 (默认 detailed; auto 按页面类型选择, 或 tldr/tutorial/paper/news 等风格及自定义模板名)")
+	runCmd.Flags().BoolVar(&flashcards, "flashcards", false,
+		"生成闪卡 (Spaced Repetition 插件语法,可用 export anki 导出)")
+	runCmd.Flags().StringVar(&modelName, "model", "",
+		"本次使用的模型 (覆盖 model.model_name, 与备用模型同名时使用其接口配置)")
+	runCmd.Flags().IntVar(&maxTokens, "max-tokens-budget", 0,
+		"本次运行最多消耗的 token 数,达到后停止处理剩余 URL (覆盖配置)")
+	runCmd.Flags().Float64Var(&maxCost, "max-cost", 0,
+		"本次运行最多花费的费用 (按配置的模型价格计算),达到后停止处理剩余 URL (覆盖配置)")
 }
@@ -47,6 +47,7 @@ func main() {
 	if err != nil {
 		log.Fatal("创建工具失败", zap.Error(err))
 	}
+	defer webNoteTool.Close()
 
 	// 5. 演示使用
 	demo(ctx, webNoteTool)
@@ -0,0 +1,198 @@
+// Package classify 根据页面元数据、URL 和结构判断页面类型,并为其选择总结风格
+package classify
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 页面类型
+const (
+	TypeArticle   = "article"   // 普通文章 (无法判断时的默认类型)
+	TypeTutorial  = "tutorial"  // 教程、入门指南
+	TypeReference = "reference" // API 参考、技术文档
+	TypePaper     = "paper"     // 学术论文
+	TypeNews      = "news"      // 新闻报道
+	TypeOpinion   = "opinion"   // 评论、观点文章
+	TypeProduct   = "product"   // 产品介绍、定价页
+	TypeChangelog = "changelog" // 更新日志、发布说明
+	TypeVideo     = "video"     // 视频页
+)
+
+// Types 全部页面类型
+var Types = []string{
+	TypeArticle, TypeTutorial, TypeReference, TypePaper, TypeNews,
+	TypeOpinion, TypeProduct, TypeChangelog, TypeVideo,
+}
+
+// styles 页面类型对应的总结风格
+var styles = map[string]string{
+	TypeArticle:   "detailed",
+	TypeTutorial:  "tutorial",
+	TypeReference: "reference",
+	TypePaper:     "paper",
+	TypeNews:      "news",
+	TypeOpinion:   "opinion",
+	TypeProduct:   "product",
+	TypeChangelog: "changelog",
+	TypeVideo:     "tldr", // 视频页正文多为简介,只做速览
+}
+
+// Style 页面类型对应的总结风格,未知类型使用 detailed
+func Style(pageType string) string {
+	if style, ok := styles[pageType]; ok {
+		return style
+	}
+	return styles[TypeArticle]
+}
+
+// Parse 从文本 (如 LLM 回答) 中识别页面类型,无法识别时返回空字符串
+func Parse(text string) string {
+	text = strings.ToLower(text)
+	best, bestIndex := "", -1
+	for _, t := range Types {
+		if i := strings.Index(text, t); i >= 0 && (bestIndex < 0 || i < bestIndex) {
+			best, bestIndex = t, i
+		}
+	}
+	return best
+}
+
+// Signals 分类依据,由抓取器从页面中收集
+type Signals struct {
+	URL         string
+	Title       string
+	Content     string
+	MIMEType    string   // 响应内容类型,如 application/pdf
+	Site        string   // 站点提取器名称
+	OGType      string   // og:type
+	SchemaTypes []string // JSON-LD / microdata 中的 @type
+	CodeBlocks  int      // pre 代码块数量
+	HasVideo    bool     // 页面包含 video 标签或视频网站的内嵌播放器
+}
+
+// schemaTypes schema.org 类型对应的页面类型
+var schemaTypes = map[string]string{
+	"scholarlyarticle":     TypePaper,
+	"newsarticle":          TypeNews,
+	"reportagenewsarticle": TypeNews,
+	"analysisnewsarticle":  TypeNews,
+	"opinionnewsarticle":   TypeOpinion,
+	"reviewnewsarticle":    TypeOpinion,
+	"techarticle":          TypeTutorial,
+	"howto":                TypeTutorial,
+	"apireference":         TypeReference,
+	"product":              TypeProduct,
+	"softwareapplication":  TypeProduct,
+	"videoobject":          TypeVideo,
+}
+
+// siteTypes 站点提取器对应的页面类型
+var siteTypes = map[string]string{
+	"arxiv":     TypePaper,
+	"github":    TypeReference,
+	"wikipedia": TypeReference,
+}
+
+// rule 正则匹配规则
+type rule struct {
+	re       *regexp.Regexp
+	pageType string
+}
+
+// urlRules 按顺序匹配的 URL 路径规则 (路径已转为小写)
+var urlRules = []rule{
+	{regexp.MustCompile(`(^|/)(changelog|changes|release-notes|releases|releasenotes|whats-new)(/|\.|$)`), TypeChangelog},
+	{regexp.MustCompile(`\.pdf$`), TypePaper},
+	{regexp.MustCompile(`(^|/)(api|apis|reference|references|ref|pkg|javadoc|godoc)(/|$)`), TypeReference},
+	{regexp.MustCompile(`(^|/)(tutorials?|guides?|getting-started|quickstart|quick-start|how-?to|learn|codelabs?)(/|-|$)`), TypeTutorial},
+	{regexp.MustCompile(`(^|/)(opinions?|editorials?|columns?|commentary|op-ed)(/|$)`), TypeOpinion},
+	{regexp.MustCompile(`(^|/)(news|press|press-releases?)(/|$)`), TypeNews},
+	{regexp.MustCompile(`(^|/)(products?|pricing|features|plans)(/|$)`), TypeProduct},
+	{regexp.MustCompile(`(^|/)docs?(/|$)`), TypeReference},
+}
+
+// titleRules 标题规则
+var titleRules = []rule{
+	{regexp.MustCompile(`(?i)(changelog|release notes|what's new in|更新日志|发布说明|版本说明)`), TypeChangelog},
+	{regexp.MustCompile(`(?i)(tutorial|how to|step[- ]by[- ]step|getting started|教程|入门|手把手|指南)`), TypeTutorial},
+}
+
+// videoHosts 视频网站及其播放页路径前缀
+var videoHosts = map[string]string{
+	"youtube.com":  "/watch",
+	"youtu.be":     "/",
+	"vimeo.com":    "/",
+	"bilibili.com": "/video/",
+}
+
+// 结构启发式阈值
+const (
+	minCodeBlocks       = 3    // 代码块达到该数量视为教程
+	maxVideoPageContent = 1500 // 含视频且正文短于该字符数视为视频页
+)
+
+// Classify 按规则判断页面类型,依次使用站点/内容类型、视频网站、schema.org、og:type、URL、标题和页面结构
+// 无法判断时返回 TypeArticle
+func Classify(s Signals) string {
+	if t, ok := siteTypes[s.Site]; ok {
+		return t
+	}
+	if strings.Contains(s.MIMEType, "pdf") {
+		return TypePaper
+	}
+
+	u, _ := url.Parse(s.URL)
+	if u != nil && isVideoURL(u) {
+		return TypeVideo
+	}
+
+	for _, schemaType := range s.SchemaTypes {
+		if t, ok := schemaTypes[strings.ToLower(schemaType)]; ok {
+			// 文章内嵌视频时 JSON-LD 中常出现 VideoObject,交给后面的视频启发式判断
+			if t != TypeVideo {
+				return t
+			}
+		}
+	}
+
+	ogType := strings.ToLower(s.OGType)
+	switch {
+	case strings.HasPrefix(ogType, "video"):
+		return TypeVideo
+	case ogType == "product" || strings.HasPrefix(ogType, "product."):
+		return TypeProduct
+	}
+
+	if u != nil {
+		path := strings.ToLower(u.Path)
+		for _, r := range urlRules {
+			if r.re.MatchString(path) {
+				return r.pageType
+			}
+		}
+	}
+	for _, r := range titleRules {
+		if r.re.MatchString(s.Title) {
+			return r.pageType
+		}
+	}
+
+	if s.HasVideo && utf8.RuneCountInString(strings.TrimSpace(s.Content)) < maxVideoPageContent {
+		return TypeVideo
+	}
+	if s.CodeBlocks >= minCodeBlocks {
+		return TypeTutorial
+	}
+	return TypeArticle
+}
+
+// isVideoURL 是否为视频网站的播放页
+func isVideoURL(u *url.URL) bool {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	host = strings.TrimPrefix(host, "m.")
+	prefix, ok := videoHosts[host]
+	return ok && strings.HasPrefix(u.Path, prefix) && len(u.Path) > 1
+}
@@ -0,0 +1,69 @@
+package classify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals Signals
+		want    string
+	}{
+		{"arXiv", Signals{URL: "https://arxiv.org/abs/1706.03762", Site: "arxiv"}, TypePaper},
+		{"PDF", Signals{URL: "https://example.com/files/report", MIMEType: "application/pdf"}, TypePaper},
+		{"YouTube", Signals{URL: "https://www.youtube.com/watch?v=abc"}, TypeVideo},
+		{"B 站首页不算视频页", Signals{URL: "https://www.bilibili.com/"}, TypeArticle},
+		{"schema 新闻", Signals{URL: "https://example.com/2024/05/a", SchemaTypes: []string{"NewsArticle"}}, TypeNews},
+		{"schema 评论", Signals{URL: "https://example.com/a", SchemaTypes: []string{"WebPage", "OpinionNewsArticle"}}, TypeOpinion},
+		{"schema 内嵌视频不算视频页", Signals{URL: "https://example.com/a", SchemaTypes: []string{"VideoObject"}, Content: longText}, TypeArticle},
+		{"og:type 产品", Signals{URL: "https://shop.example.com/item/1", OGType: "product"}, TypeProduct},
+		{"og:type 视频", Signals{URL: "https://example.com/v/1", OGType: "video.other"}, TypeVideo},
+		{"更新日志路径", Signals{URL: "https://github.com/golang/go/releases/tag/go1.22.0"}, TypeChangelog},
+		{"CHANGELOG 文件", Signals{URL: "https://example.com/CHANGELOG.md"}, TypeChangelog},
+		{"API 参考", Signals{URL: "https://docs.python.org/3/reference/datamodel.html"}, TypeReference},
+		{"教程路径", Signals{URL: "https://go.dev/doc/tutorial/getting-started"}, TypeTutorial},
+		{"文档路径", Signals{URL: "https://example.com/docs/config"}, TypeReference},
+		{"新闻路径", Signals{URL: "https://example.com/news/2024/launch"}, TypeNews},
+		{"定价页", Signals{URL: "https://example.com/pricing"}, TypeProduct},
+		{"标题: 发布说明", Signals{URL: "https://example.com/blog/v2", Title: "Release Notes for v2.0"}, TypeChangelog},
+		{"标题: 教程", Signals{URL: "https://example.com/blog/1", Title: "手把手教你写 Go 插件"}, TypeTutorial},
+		{"短正文 + 视频", Signals{URL: "https://example.com/talks/1", HasVideo: true, Content: "演讲简介"}, TypeVideo},
+		{"长正文 + 视频", Signals{URL: "https://example.com/blog/1", HasVideo: true, Content: longText}, TypeArticle},
+		{"代码块多", Signals{URL: "https://example.com/blog/1", CodeBlocks: 4}, TypeTutorial},
+		{"普通文章", Signals{URL: "https://example.com/blog/1", Title: "我的一年"}, TypeArticle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.signals); got != tt.want {
+				t.Errorf("Classify() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// longText 超过视频页阈值的正文
+var longText = strings.Repeat("字", maxVideoPageContent+1)
+
+func TestStyleAndParse(t *testing.T) {
+	for pageType, want := range map[string]string{
+		TypeArticle: "detailed", TypePaper: "paper", TypeChangelog: "changelog", TypeVideo: "tldr", "": "detailed", "unknown": "detailed",
+	} {
+		if got := Style(pageType); got != want {
+			t.Errorf("Style(%q) = %q, want %q", pageType, got, want)
+		}
+	}
+
+	for text, want := range map[string]string{
+		"tutorial":        TypeTutorial,
+		"类型: Changelog\n": TypeChangelog,
+		"news (新闻报道)":     TypeNews,
+		"我无法判断":           "",
+	} {
+		if got := Parse(text); got != want {
+			t.Errorf("Parse(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
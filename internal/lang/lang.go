@@ -0,0 +1,205 @@
+// Package lang 提供基于文字系统和常用词的轻量语言识别
+package lang
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// minLetters 少于该字母数时不做判断
+const minLetters = 20
+
+// sampleRunes 只统计前若干字符,长文档无需全文扫描
+const sampleRunes = 5000
+
+// stopwords 拉丁字母语言的常用词,用于区分英语、德语、法语和西班牙语
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "for", "with", "this", "are"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "mit", "ein", "eine", "für", "auf", "auch"},
+	"fr": {"le", "la", "les", "et", "des", "est", "une", "pour", "dans", "que", "pas", "sur"},
+	"es": {"el", "los", "las", "y", "es", "una", "para", "con", "por", "que", "del", "se"},
+}
+
+// Detect 识别文本语言,返回 ISO 639-1 代码 (zh/ja/ko/ru/ar/th/en/de/fr/es)
+// 文本过短或无法判断时返回空字符串
+func Detect(text string) string {
+	var han, kana, hangul, cyrillic, arabic, thai, latin int
+	n := 0
+	for _, r := range text {
+		if n >= sampleRunes {
+			break
+		}
+		n++
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Arabic, r):
+			arabic++
+		case unicode.Is(unicode.Thai, r):
+			thai++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	// 中日文单字信息量大,按 3 个拉丁字母折算,避免中文夹杂英文术语时被判为英文
+	cjk := (han + kana) * 3
+	total := cjk + hangul*3 + cyrillic + arabic + thai + latin
+	if total < minLetters {
+		return ""
+	}
+
+	// 含一定比例假名即为日文 (日文同样大量使用汉字)
+	if kana > 0 && kana*10 >= han+kana && cjk >= latin {
+		return "ja"
+	}
+
+	scores := []struct {
+		lang  string
+		count int
+	}{
+		{"zh", cjk},
+		{"ko", hangul * 3},
+		{"ru", cyrillic},
+		{"ar", arabic},
+		{"th", thai},
+		{"latin", latin},
+	}
+	best := scores[0]
+	for _, s := range scores[1:] {
+		if s.count > best.count {
+			best = s
+		}
+	}
+	if best.lang != "latin" {
+		return best.lang
+	}
+	return detectLatin(text)
+}
+
+// detectLatin 按常用词出现次数区分拉丁字母语言,默认英语
+func detectLatin(text string) string {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if len(words) > sampleRunes/5 {
+		words = words[:sampleRunes/5]
+	}
+
+	index := make(map[string][]string)
+	for lang, list := range stopwords {
+		for _, w := range list {
+			index[w] = append(index[w], lang)
+		}
+	}
+	for _, w := range words {
+		for _, lang := range index[w] {
+			counts[lang]++
+		}
+	}
+
+	best, bestCount := "en", counts["en"]
+	for _, lang := range []string{"de", "fr", "es"} {
+		if counts[lang] > bestCount {
+			best, bestCount = lang, counts[lang]
+		}
+	}
+	return best
+}
+
+// Base 语言标签的主语言部分,如 zh-CN -> zh、en_US -> en
+func Base(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+// tagPattern BCP-47 语言标签 (主语言加若干子标签,如 zh-CN、zh-Hant-TW),兼容下划线分隔
+var tagPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(?:[-_][A-Za-z0-9]{1,8})*$`)
+
+// Valid 是否为合法的语言标签
+// 标签会写入笔记 frontmatter 和提示词,来自请求或网页的值需先校验
+func Valid(tag string) bool {
+	return len(tag) <= 35 && tagPattern.MatchString(tag)
+}
+
+// SameAsSource 笔记语言与原文相同
+const SameAsSource = "source"
+
+// Default 默认笔记语言
+const Default = "zh-CN"
+
+// ValidSetting 是否为合法的笔记语言设置: 语言标签或 source (same-as-source)
+func ValidSetting(setting string) bool {
+	setting = strings.TrimSpace(setting)
+	switch strings.ToLower(setting) {
+	case SameAsSource, "same-as-source":
+		return true
+	}
+	return Valid(setting)
+}
+
+// Resolve 根据配置和原文语言确定笔记语言
+// setting 为空时使用默认语言;为 source (或 same-as-source) 时跟随原文,原文语言未知时使用默认语言
+func Resolve(setting, source string) string {
+	setting = strings.TrimSpace(setting)
+	switch strings.ToLower(setting) {
+	case "":
+		return Default
+	case SameAsSource, "same-as-source":
+		// 检测结果只有主语言,中文按默认的简体中文处理
+		if source == "" || Base(source) == "zh" {
+			return Default
+		}
+		return source
+	}
+	return setting
+}
+
+// names 提示词中使用的语言名称
+var names = map[string]string{
+	"zh-cn": "简体中文",
+	"zh-tw": "繁體中文",
+	"zh-hk": "繁體中文",
+	"zh":    "简体中文",
+	"en":    "English",
+	"ja":    "日本語",
+	"ko":    "한국어",
+	"ru":    "Русский",
+	"de":    "Deutsch",
+	"fr":    "Français",
+	"es":    "Español",
+	"ar":    "العربية",
+	"th":    "ไทย",
+}
+
+// Name 语言标签对应的语言名称,未知标签原样返回,不是合法的语言标签时返回默认语言的名称
+func Name(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if !Valid(tag) {
+		return names[strings.ToLower(Default)]
+	}
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if name, ok := names[key]; ok {
+		return name
+	}
+	if name, ok := names[Base(key)]; ok {
+		return name
+	}
+	return tag
+}
+
+// Same 两个语言标签的主语言是否相同
+func Same(a, b string) bool {
+	return a != "" && b != "" && Base(a) == Base(b)
+}
@@ -0,0 +1,92 @@
+package note
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/fromsko/krio/internal/summarizer"
+)
+
+// ankiHeader Anki 文本导入的文件头 (Anki 2.1.55+)
+// guid 列使重新导入时更新已有笔记而不是重复添加
+const ankiHeader = `#separator:tab
+#html:true
+#guid column:1
+#notetype column:2
+#deck column:3
+#tags column:6
+`
+
+// WriteAnkiTSV 将卡片写为 Anki 可导入的 TSV
+// 问答卡使用 Basic 笔记类型,填空卡使用 Cloze 笔记类型 (==挖空== 转换为 {{cN::...}}),
+// 背面附上来源链接;相同 ID 的卡片只写入一次
+func WriteAnkiTSV(w io.Writer, cards []Card, deck string) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(ankiHeader)
+
+	seen := make(map[string]bool)
+	for _, card := range cards {
+		if seen[card.ID] {
+			continue
+		}
+		seen[card.ID] = true
+
+		noteType, front, back := "Basic", ankiField(card.Front), ankiField(card.Back)
+		if card.Type == summarizer.CardCloze {
+			noteType, front, back = "Cloze", ankiCloze(front), ""
+		}
+		if link := ankiSourceLink(card); link != "" {
+			if back != "" {
+				back += "<br><br>"
+			}
+			back += link
+		}
+
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			card.ID, noteType, ankiField(deck), front, back, ankiTags(card.Tags))
+	}
+	return bw.Flush()
+}
+
+// ankiField 转义字段: HTML 特殊字符转义,换行转为 <br>,制表符转为空格
+func ankiField(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, "\t", " ")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// ankiCloze 将 ==挖空== 依次转换为 {{c1::...}}、{{c2::...}}
+func ankiCloze(s string) string {
+	n := 0
+	return clozeMark.ReplaceAllStringFunc(s, func(m string) string {
+		n++
+		return fmt.Sprintf("{{c%d::%s}}", n, strings.Trim(m, "="))
+	})
+}
+
+// ankiSourceLink 来源链接
+func ankiSourceLink(card Card) string {
+	if card.Source == "" {
+		return ""
+	}
+	text := card.Title
+	if text == "" {
+		text = card.Source
+	}
+	return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(card.Source), ankiField(text))
+}
+
+// ankiTags 标签以空格分隔,标签内的空格替换为下划线,并附加 krio 标签
+func ankiTags(tags []string) string {
+	out := []string{DefaultDeck}
+	for _, tag := range tags {
+		if tag = strings.Join(strings.Fields(tag), "_"); tag != "" && tag != DefaultDeck {
+			out = append(out, tag)
+		}
+	}
+	return strings.Join(out, " ")
+}
@@ -0,0 +1,182 @@
+package note
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/internal/summarizer"
+)
+
+// DefaultDeck 默认卡组名
+const DefaultDeck = "krio"
+
+// flashcardHeading 笔记中闪卡区块的标题
+const flashcardHeading = "## 🃏 闪卡"
+
+// Deck 配置的卡组名
+func Deck(cfg *config.FlashcardConfig) string {
+	if cfg.Deck == "" {
+		return DefaultDeck
+	}
+	return cfg.Deck
+}
+
+// Card 从笔记中解析出的闪卡
+type Card struct {
+	ID       string // 稳定的卡片 ID (来源地址 + 卡片正面的哈希),重新导出时用于更新而不是重复添加
+	Type     string // summarizer.CardQA 或 summarizer.CardCloze
+	Front    string // 问答卡的问题,填空卡的句子 (挖空用 ==...== 标出)
+	Back     string // 问答卡的答案
+	Source   string // 笔记来源地址
+	Title    string // 笔记标题
+	NotePath string // 笔记文件路径 (相对于收集目录)
+	Tags     []string
+}
+
+// generateFlashcardSection 渲染闪卡区块 (Obsidian Spaced Repetition 插件语法)
+// 问答卡为 "问题::答案",填空卡为带 ==挖空== 的句子,卡片之间空行分隔
+func (g *Generator) generateFlashcardSection(cards []summarizer.Flashcard) string {
+	if len(cards) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(flashcardHeading + "\n\n")
+	sb.WriteString(fmt.Sprintf("#flashcards/%s\n\n", strings.ReplaceAll(Deck(&g.cfg.Flashcards), " ", "-")))
+	for _, card := range cards {
+		switch card.Type {
+		case summarizer.CardQA:
+			// :: 是问答卡的分隔符,不能出现在问题和答案中
+			sb.WriteString(fmt.Sprintf("%s::%s\n\n",
+				strings.ReplaceAll(card.Question, "::", ":"), strings.ReplaceAll(card.Answer, "::", ":")))
+		case summarizer.CardCloze:
+			sb.WriteString(strings.ReplaceAll(card.Text, "::", ":") + "\n\n")
+		}
+	}
+	return sb.String()
+}
+
+// srComment Spaced Repetition 插件写入的复习记录
+var srComment = regexp.MustCompile(`\s*<!--SR:.*?-->`)
+
+// clozeMark 填空卡的挖空标记
+var clozeMark = regexp.MustCompile(`==([^=\n]+)==`)
+
+// ParseCards 从笔记 Markdown 中解析闪卡区块的卡片
+// 支持单行问答卡 (问题::答案)、多行问答卡 (问题 / ? / 答案) 和填空卡 (==挖空==),
+// 忽略插件写入的复习记录,因此在 Obsidian 中复习或修改过的卡片仍可导出
+func ParseCards(markdown string) []Card {
+	source, title, tags := parseFrontmatter(markdown)
+
+	start := strings.Index(markdown, "\n"+flashcardHeading)
+	if start < 0 {
+		return nil
+	}
+	section := markdown[start+len(flashcardHeading)+1:]
+	if end := strings.Index(section, "\n## "); end >= 0 {
+		section = section[:end]
+	}
+
+	var cards []Card
+	for _, block := range strings.Split(strings.ReplaceAll(section, "\r\n", "\n"), "\n\n") {
+		block = strings.TrimSpace(srComment.ReplaceAllString(block, ""))
+		if block == "" || strings.HasPrefix(block, "#flashcards") {
+			continue
+		}
+
+		card := Card{Source: source, Title: title, Tags: tags}
+		if front, back, ok := strings.Cut(block, "\n?\n"); ok {
+			card.Type, card.Front, card.Back = summarizer.CardQA, strings.TrimSpace(front), strings.TrimSpace(back)
+		} else if front, back, ok := strings.Cut(block, "::"); ok {
+			card.Type, card.Front, card.Back = summarizer.CardQA, strings.TrimSpace(front), strings.TrimSpace(back)
+		} else if clozeMark.MatchString(block) {
+			card.Type, card.Front = summarizer.CardCloze, block
+		} else {
+			continue
+		}
+		if card.Front == "" || (card.Type == summarizer.CardQA && card.Back == "") {
+			continue
+		}
+		card.ID = CardID(source, card.Front)
+		cards = append(cards, card)
+	}
+	return cards
+}
+
+// CollectCards 收集目录下所有 Markdown 笔记中的闪卡 (跳过 . 开头的目录,如 .obsidian)
+func CollectCards(root string) ([]Card, error) {
+	var cards []Card
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("读取笔记失败: %w", err)
+		}
+		rel, _ := filepath.Rel(root, path)
+		for _, card := range ParseCards(string(data)) {
+			card.NotePath = filepath.ToSlash(rel)
+			cards = append(cards, card)
+		}
+		return nil
+	})
+	return cards, err
+}
+
+// CardID 卡片 ID: 来源地址和卡片正面的哈希
+// 同一网页重新生成笔记时,问题不变的卡片 ID 不变
+func CardID(source, front string) string {
+	sum := sha256.Sum256([]byte(source + "\n" + strings.Join(strings.Fields(front), " ")))
+	return "krio-" + hex.EncodeToString(sum[:])[:16]
+}
+
+// parseFrontmatter 读取笔记 frontmatter 中的来源地址、标题和标签
+// 只处理本程序生成的单行字段,不做完整的 YAML 解析
+func parseFrontmatter(markdown string) (source, title string, tags []string) {
+	rest, ok := strings.CutPrefix(markdown, "---\n")
+	if !ok {
+		return "", "", nil
+	}
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return "", "", nil
+	}
+	for _, line := range strings.Split(rest[:end], "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "source":
+			source = value
+		case "title":
+			title = value
+		case "tags":
+			for _, tag := range strings.Split(strings.Trim(value, "[]"), ",") {
+				if tag = strings.Trim(strings.TrimSpace(tag), `"'`); tag != "" {
+					tags = append(tags, tag)
+				}
+			}
+		}
+	}
+	return source, title, tags
+}
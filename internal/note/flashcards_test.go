@@ -0,0 +1,104 @@
+package note
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/internal/summarizer"
+)
+
+func TestFlashcards_RoundTrip(t *testing.T) {
+	gen := &Generator{cfg: &config.NoteConfig{Flashcards: config.FlashcardConfig{Deck: "Go 学习"}}}
+	summary := &summarizer.Summary{
+		Title:       "Go 内存模型",
+		OneSentence: "一句话",
+		Tags:        []string{"go", "memory model"},
+		Flashcards: []summarizer.Flashcard{
+			{Type: summarizer.CardQA, Question: "channel 发送与接收的先后关系?", Answer: "发送 happens before 对应的接收完成"},
+			{Type: summarizer.CardCloze, Text: "Go 的数据竞争检测器通过 ==-race== 参数启用"},
+			{Type: summarizer.CardQA, Question: "std::mutex 的 Go 等价物?", Answer: "sync.Mutex"},
+		},
+	}
+
+	markdown := gen.Generate(summary, "https://go.dev/ref/mem", nil)
+	for _, s := range []string{
+		"## 🃏 闪卡\n\n#flashcards/Go-学习\n\n",
+		"channel 发送与接收的先后关系?::发送 happens before 对应的接收完成\n\n",
+		"std:mutex 的 Go 等价物?::sync.Mutex\n",
+	} {
+		if !strings.Contains(markdown, s) {
+			t.Errorf("note missing %q:\n%s", s, markdown)
+		}
+	}
+
+	// 模拟在 Obsidian 中复习后插件追加的记录,以及手写的多行卡片
+	markdown = strings.Replace(markdown, "sync.Mutex\n", "sync.Mutex <!--SR:!2026-01-02,3,250-->\n", 1)
+	markdown = strings.Replace(markdown, "## 🃏 闪卡\n\n", "## 🃏 闪卡\n\n什么是 happens-before?\n?\n一种偏序关系\n\n", 1)
+
+	cards := ParseCards(markdown)
+	if len(cards) != 4 {
+		t.Fatalf("ParseCards() = %d cards: %+v", len(cards), cards)
+	}
+	if c := cards[0]; c.Type != summarizer.CardQA || c.Front != "什么是 happens-before?" || c.Back != "一种偏序关系" {
+		t.Errorf("multi-line card = %+v", c)
+	}
+	if c := cards[2]; c.Type != summarizer.CardCloze || !strings.Contains(c.Front, "==-race==") {
+		t.Errorf("cloze card = %+v", c)
+	}
+	if c := cards[3]; c.Back != "sync.Mutex" || c.Source != "https://go.dev/ref/mem" || len(c.Tags) != 2 {
+		t.Errorf("reviewed card = %+v", c)
+	}
+	if cards[1].ID != CardID("https://go.dev/ref/mem", "channel 发送与接收的先后关系?") || cards[1].ID == cards[3].ID {
+		t.Errorf("card IDs not stable: %s, %s", cards[1].ID, cards[3].ID)
+	}
+
+	if ParseCards("# 没有闪卡的笔记\n") != nil {
+		t.Error("note without flashcards should have no cards")
+	}
+}
+
+func TestWriteAnkiTSV(t *testing.T) {
+	cards := []Card{
+		{ID: "krio-1", Type: summarizer.CardQA, Front: "a < b?", Back: "是\n真的", Source: "https://example.com", Title: "示例", Tags: []string{"go", "memory model"}},
+		{ID: "krio-2", Type: summarizer.CardCloze, Front: "==Go== 由 ==Google== 开发", Source: "https://example.com"},
+		{ID: "krio-1", Type: summarizer.CardQA, Front: "重复", Back: "重复"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAnkiTSV(&buf, cards, "krio"); err != nil {
+		t.Fatalf("WriteAnkiTSV() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 8 || lines[2] != "#guid column:1" {
+		t.Fatalf("output:\n%s", buf.String())
+	}
+
+	want := []string{
+		"krio-1\tBasic\tkrio\ta &lt; b?\t是<br>真的<br><br><a href=\"https://example.com\">示例</a>\tkrio go memory_model",
+		"krio-2\tCloze\tkrio\t{{c1::Go}} 由 {{c2::Google}} 开发\t<a href=\"https://example.com\">https://example.com</a>\tkrio",
+	}
+	for i, w := range want {
+		if lines[6+i] != w {
+			t.Errorf("line %d = %q, want %q", i, lines[6+i], w)
+		}
+	}
+}
+
+func TestCollectCards(t *testing.T) {
+	root := t.TempDir()
+	content := "---\nsource: https://example.com\n---\n\n# T\n\n## 🃏 闪卡\n\n#flashcards/krio\n\nQ::A\n"
+	os.MkdirAll(filepath.Join(root, "Articles"), 0755)
+	os.MkdirAll(filepath.Join(root, ".obsidian"), 0755)
+	os.WriteFile(filepath.Join(root, "Articles", "a.md"), []byte(content), 0644)
+	os.WriteFile(filepath.Join(root, ".obsidian", "b.md"), []byte(content), 0644)
+	os.WriteFile(filepath.Join(root, "c.txt"), []byte(content), 0644)
+
+	cards, err := CollectCards(root)
+	if err != nil || len(cards) != 1 || cards[0].NotePath != "Articles/a.md" {
+		t.Errorf("CollectCards() = %+v, %v", cards, err)
+	}
+}
@@ -22,16 +22,42 @@ func NewGenerator(cfg *config.NoteConfig) *Generator {
 	return &Generator{cfg: cfg}
 }
 
+// Meta 笔记附加元数据 (写入 frontmatter)
+type Meta struct {
+	PageCount int      // PDF 页数
+	LeadImage string   // 头图附件路径 (vault 内相对路径)
+	Images    []string // 正文图片附件路径
+
+	FetchedAt     time.Time // 网页抓取时间
+	ArchiveInline string    // 内联存档的原文 Markdown (渲染为折叠区块)
+	ArchivePath   string    // 原文存档文件路径
+	SnapshotPath  string    // HTML 快照路径
+
+	ArchivedFrom string    // 原链接失效时使用的存档快照地址
+	ArchivedAt   time.Time // 存档快照时间
+
+	Partial       bool   // 正文可能不完整 (登录墙/付费墙)
+	PartialReason string // 判定为不完整的原因
+
+	Language       string // 笔记语言
+	SourceLanguage string // 原文语言
+}
+
 // Generate 生成 Markdown 笔记
-func (g *Generator) Generate(summary *summarizer.Summary, sourceURL string) string {
+// meta 可为 nil
+func (g *Generator) Generate(summary *summarizer.Summary, sourceURL string, meta *Meta) string {
 	now := time.Now()
 	timestamp := now.Format("2006-01-02T15:04:05")
 
+	if meta == nil {
+		meta = &Meta{}
+	}
+
 	// 生成 frontmatter
-	frontmatter := g.generateFrontmatter(summary, sourceURL, timestamp)
+	frontmatter := g.generateFrontmatter(summary, sourceURL, timestamp, meta)
 
 	// 生成内容
-	content := g.generateContent(summary)
+	content := g.generateContent(summary, meta)
 
 	// 组合
 	note := fmt.Sprintf("%s\n\n%s", frontmatter, content)
@@ -40,7 +66,7 @@ func (g *Generator) Generate(summary *summarizer.Summary, sourceURL string) stri
 }
 
 // generateFrontmatter 生成 frontmatter
-func (g *Generator) generateFrontmatter(summary *summarizer.Summary, sourceURL, timestamp string) string {
+func (g *Generator) generateFrontmatter(summary *summarizer.Summary, sourceURL, timestamp string, meta *Meta) string {
 	tags := g.formatTags(summary.Tags)
 	filename := g.generateFilename(summary.Title, summary.Tags)
 
@@ -53,7 +79,7 @@ filename: %s
 created_at: %s
 updated_at: %s
 id: %s
----
+%s---
 `,
 		escapeYAML(summary.Title),
 		escapeYAML(sourceURL),
@@ -63,13 +89,65 @@ id: %s
 		timestamp,
 		timestamp,
 		xid.New().String(),
+		g.generateSummaryFields(summary)+g.generateMetaFields(meta),
 	)
 
 	return frontmatter
 }
 
+// generateSummaryFields 生成模型、总结风格、页面类型和提示词版本字段
+func (g *Generator) generateSummaryFields(summary *summarizer.Summary) string {
+	var sb strings.Builder
+	if summary.Model != "" {
+		sb.WriteString(fmt.Sprintf("model: %s\n", escapeYAML(summary.Model)))
+	}
+	if summary.Style != "" {
+		sb.WriteString(fmt.Sprintf("style: %s\n", summary.Style))
+	}
+	if summary.ContentType != "" {
+		sb.WriteString(fmt.Sprintf("content_type: %s\n", summary.ContentType))
+	}
+	if summary.PromptVersion != "" {
+		sb.WriteString(fmt.Sprintf("prompt_version: %s\n", summary.PromptVersion))
+	}
+	return sb.String()
+}
+
+// generateMetaFields 生成附加元数据字段
+func (g *Generator) generateMetaFields(meta *Meta) string {
+	var sb strings.Builder
+
+	if meta.PageCount > 0 {
+		sb.WriteString(fmt.Sprintf("pages: %d\n", meta.PageCount))
+	}
+	if !meta.FetchedAt.IsZero() {
+		sb.WriteString(fmt.Sprintf("fetched_at: %s\n", meta.FetchedAt.Format("2006-01-02T15:04:05")))
+	}
+	if meta.ArchivePath != "" {
+		sb.WriteString(fmt.Sprintf("archive_path: %s\n", escapeYAML(meta.ArchivePath)))
+	}
+	if meta.SnapshotPath != "" {
+		sb.WriteString(fmt.Sprintf("snapshot_path: %s\n", escapeYAML(meta.SnapshotPath)))
+	}
+	if meta.Language != "" {
+		sb.WriteString(fmt.Sprintf("language: %s\n", escapeYAML(meta.Language)))
+	}
+	if meta.SourceLanguage != "" {
+		sb.WriteString(fmt.Sprintf("source_language: %s\n", escapeYAML(meta.SourceLanguage)))
+	}
+	if meta.Partial {
+		sb.WriteString("partial: true\n")
+	}
+	if meta.ArchivedFrom != "" {
+		sb.WriteString(fmt.Sprintf("archived_from: %s\n", escapeYAML(meta.ArchivedFrom)))
+		sb.WriteString(fmt.Sprintf("archived_at: %s\n", meta.ArchivedAt.Format("2006-01-02")))
+	}
+
+	return sb.String()
+}
+
 // generateContent 生成内容
-func (g *Generator) generateContent(summary *summarizer.Summary) string {
+func (g *Generator) generateContent(summary *summarizer.Summary, meta *Meta) string {
 	var sb strings.Builder
 
 	// 标题
@@ -78,15 +156,51 @@ func (g *Generator) generateContent(summary *summarizer.Summary) string {
 	// 一句话总结
 	sb.WriteString(fmt.Sprintf("> %s\n\n", summary.OneSentence))
 
+	// 内容不完整提示
+	if meta.Partial {
+		sb.WriteString("> [!warning] 内容可能不完整\n")
+		sb.WriteString("> 原文需要登录或付费才能查看全文,本笔记仅基于可见部分生成")
+		if meta.PartialReason != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", meta.PartialReason))
+		}
+		sb.WriteString("\n\n")
+	}
+
+	// 原链接失效提示
+	if meta.ArchivedFrom != "" {
+		sb.WriteString(fmt.Sprintf("> [!warning] 原链接已失效,内容来自 %s 的[存档快照](%s)\n\n",
+			meta.ArchivedAt.Format("2006-01-02"), meta.ArchivedFrom))
+	}
+
+	// 头图
+	if meta.LeadImage != "" {
+		sb.WriteString(fmt.Sprintf("![[%s]]\n\n", meta.LeadImage))
+	}
+
 	// 核心要点
 	if len(summary.KeyPoints) > 0 {
 		sb.WriteString("## 📌 核心要点\n\n")
 		for i, point := range summary.KeyPoints {
 			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, point))
+			// 原文引用缩进在要点下方
+			if i < len(summary.KeyPointQuotes) && summary.KeyPointQuotes[i] != "" {
+				sb.WriteString(fmt.Sprintf("    > %s\n", strings.ReplaceAll(summary.KeyPointQuotes[i], "\n", " ")))
+			}
 		}
 		sb.WriteString("\n")
 	}
 
+	// 扩展字段: 章节、引用、代码、术语、行动项和待解决问题
+	sb.WriteString(g.generateExtendedSections(summary))
+
+	// 正文图片
+	if len(meta.Images) > 0 {
+		sb.WriteString("## 🖼️ 图片\n\n")
+		for _, image := range meta.Images {
+			sb.WriteString(fmt.Sprintf("![[%s]]\n\n", image))
+		}
+	}
+
 	// AI 生成的标签
 	if len(summary.Tags) > 0 {
 		sb.WriteString("## 🏷️ 标签\n\n")
@@ -96,9 +210,153 @@ func (g *Generator) generateContent(summary *summarizer.Summary) string {
 		sb.WriteString("\n")
 	}
 
+	// 闪卡
+	sb.WriteString(g.generateFlashcardSection(summary.Flashcards))
+
+	// 原文存档
+	sb.WriteString(g.generateArchiveSection(meta))
+
 	return sb.String()
 }
 
+// generateExtendedSections 渲染总结的扩展字段,字段为空时不输出对应区块
+func (g *Generator) generateExtendedSections(summary *summarizer.Summary) string {
+	var sb strings.Builder
+
+	if len(summary.Sections) > 0 {
+		sb.WriteString("## 🗂️ 内容结构\n\n")
+		writeSections(&sb, summary.Sections, 3)
+	}
+
+	if len(summary.Quotes) > 0 {
+		sb.WriteString("## 💬 关键引用\n\n")
+		for _, quote := range summary.Quotes {
+			sb.WriteString(strings.TrimRight("> [!quote] "+quote.Location, " ") + "\n")
+			for _, line := range strings.Split(quote.Text, "\n") {
+				sb.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(summary.CodeSnippets) > 0 {
+		sb.WriteString("## 💻 代码片段\n\n")
+		for _, snippet := range summary.CodeSnippets {
+			if snippet.Caption != "" {
+				sb.WriteString(fmt.Sprintf("**%s**\n\n", snippet.Caption))
+			}
+			fence := codeFence(snippet.Code)
+			sb.WriteString(fmt.Sprintf("%s%s\n%s\n%s\n\n", fence, snippet.Language, snippet.Code, fence))
+		}
+	}
+
+	if len(summary.Glossary) > 0 {
+		sb.WriteString("## 📖 术语表\n\n")
+		for _, term := range summary.Glossary {
+			sb.WriteString(fmt.Sprintf("- **%s**: %s\n", term.Term, term.Definition))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(summary.ActionItems) > 0 {
+		sb.WriteString("## ✅ 行动项\n\n")
+		for _, item := range summary.ActionItems {
+			sb.WriteString(fmt.Sprintf("- [ ] %s\n", item))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(summary.OpenQuestions) > 0 {
+		sb.WriteString("## ❓ 待解决问题\n\n")
+		for _, question := range summary.OpenQuestions {
+			sb.WriteString(fmt.Sprintf("- %s\n", question))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// writeSections 按层级渲染章节标题和要点
+func writeSections(sb *strings.Builder, sections []summarizer.Section, level int) {
+	for _, section := range sections {
+		sb.WriteString(fmt.Sprintf("%s %s\n\n", strings.Repeat("#", min(level, 6)), section.Heading))
+		for _, point := range section.Points {
+			sb.WriteString(fmt.Sprintf("- %s\n", point))
+		}
+		if len(section.Points) > 0 {
+			sb.WriteString("\n")
+		}
+		writeSections(sb, section.Subsections, level+1)
+	}
+}
+
+// codeFence 代码块围栏,长度超过代码中最长的连续反引号
+func codeFence(code string) string {
+	longest, run := 0, 0
+	for _, r := range code {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// generateArchiveSection 生成原文存档区块
+// 内联存档渲染为默认折叠的 callout,文件存档渲染为链接
+func (g *Generator) generateArchiveSection(meta *Meta) string {
+	if meta.ArchiveInline == "" && meta.ArchivePath == "" && meta.SnapshotPath == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 📦 原文存档\n\n")
+
+	if meta.ArchivePath != "" {
+		sb.WriteString(fmt.Sprintf("- 原文: [[%s]]\n", strings.TrimSuffix(meta.ArchivePath, ".md")))
+	}
+	if meta.SnapshotPath != "" {
+		sb.WriteString(fmt.Sprintf("- 快照: [[%s]]\n", meta.SnapshotPath))
+	}
+	if meta.ArchivePath != "" || meta.SnapshotPath != "" {
+		sb.WriteString("\n")
+	}
+
+	if meta.ArchiveInline != "" {
+		sb.WriteString("> [!quote]- 原文\n")
+		for _, line := range strings.Split(meta.ArchiveInline, "\n") {
+			sb.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// GenerateArchive 生成原文存档文件内容
+func (g *Generator) GenerateArchive(title, sourceURL, markdown string, fetchedAt time.Time) string {
+	return fmt.Sprintf(`---
+title: %s
+source: %s
+fetched_at: %s
+tags: ["archive"]
+---
+
+# %s
+
+%s
+`,
+		escapeYAML(title),
+		escapeYAML(sourceURL),
+		fetchedAt.Format("2006-01-02T15:04:05"),
+		title,
+		markdown,
+	)
+}
+
 // formatTags 格式化标签
 func (g *Generator) formatTags(tags []string) string {
 	if len(tags) == 0 {
@@ -1,9 +1,12 @@
 package note
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/internal/summarizer"
 )
 
 func TestGenerateFilename(t *testing.T) {
@@ -78,3 +81,132 @@ func TestEscapeYAML(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateArchiveSection(t *testing.T) {
+	gen := &Generator{cfg: &config.NoteConfig{}}
+
+	inline := gen.generateArchiveSection(&Meta{ArchiveInline: "# Title\n\nBody"})
+	want := "> [!quote]- 原文\n> # Title\n>\n> Body\n"
+	if !strings.Contains(inline, want) {
+		t.Errorf("inline archive = %q, want to contain %q", inline, want)
+	}
+
+	linked := gen.generateArchiveSection(&Meta{ArchivePath: "Inbox/post-archive.md", SnapshotPath: "Inbox/post.html"})
+	for _, s := range []string{"[[Inbox/post-archive]]", "[[Inbox/post.html]]"} {
+		if !strings.Contains(linked, s) {
+			t.Errorf("linked archive missing %q: %q", s, linked)
+		}
+	}
+
+	if got := gen.generateArchiveSection(&Meta{}); got != "" {
+		t.Errorf("empty meta should produce no section, got %q", got)
+	}
+}
+
+func TestGenerate_Archived(t *testing.T) {
+	gen := &Generator{cfg: &config.NoteConfig{}}
+	summary := &summarizer.Summary{Title: "旧文章", OneSentence: "一句话"}
+	meta := &Meta{
+		ArchivedFrom: "https://web.archive.org/web/20200102030405id_/https://example.com/gone",
+		ArchivedAt:   time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	note := gen.Generate(summary, "https://example.com/gone", meta)
+	for _, s := range []string{
+		"archived_from: https://web.archive.org/web/20200102030405id_/https://example.com/gone\n",
+		"archived_at: 2020-01-02\n",
+		"> [!warning] 原链接已失效,内容来自 2020-01-02 的[存档快照]",
+	} {
+		if !strings.Contains(note, s) {
+			t.Errorf("note missing %q:\n%s", s, note)
+		}
+	}
+}
+
+func TestGenerate_Partial(t *testing.T) {
+	gen := &Generator{cfg: &config.NoteConfig{}}
+	summary := &summarizer.Summary{Title: "付费文章", OneSentence: "一句话"}
+
+	note := gen.Generate(summary, "https://example.com/paid", &Meta{Partial: true, PartialReason: "页面声明 isAccessibleForFree=false"})
+	for _, s := range []string{"partial: true\n", "> [!warning] 内容可能不完整\n", "(页面声明 isAccessibleForFree=false)"} {
+		if !strings.Contains(note, s) {
+			t.Errorf("note missing %q:\n%s", s, note)
+		}
+	}
+
+	if note := gen.Generate(summary, "https://example.com/free", nil); strings.Contains(note, "partial") {
+		t.Errorf("complete note should not be marked partial:\n%s", note)
+	}
+}
+
+func TestGenerate_Language(t *testing.T) {
+	gen := &Generator{cfg: &config.NoteConfig{}}
+	summary := &summarizer.Summary{
+		Title:          "Go 内存模型",
+		OneSentence:    "一句话",
+		KeyPoints:      []string{"要点一", "要点二"},
+		KeyPointQuotes: []string{"A send on a channel happens before\nthe receive completes.", ""},
+		Model:          "glm-4.7",
+		Style:          "tldr",
+		ContentType:    "reference",
+		PromptVersion:  "tldr-0123abcd",
+	}
+
+	note := gen.Generate(summary, "https://go.dev/ref/mem", &Meta{Language: "zh-CN", SourceLanguage: "en"})
+	for _, s := range []string{
+		"model: glm-4.7\nstyle: tldr\ncontent_type: reference\n",
+		"prompt_version: tldr-0123abcd\n",
+		"language: zh-CN\n",
+		"source_language: en\n",
+		"1. 要点一\n    > A send on a channel happens before the receive completes.\n2. 要点二\n",
+	} {
+		if !strings.Contains(note, s) {
+			t.Errorf("note missing %q:\n%s", s, note)
+		}
+	}
+
+	// 语言字段转义,不能注入新的 frontmatter 字段
+	note = gen.Generate(summary, "https://go.dev/ref/mem", &Meta{Language: "en\npartial: true", SourceLanguage: "en\ntags: [x]"})
+	if strings.Contains(note, "\npartial: true\n") || strings.Contains(note, "\ntags: [x]\n") {
+		t.Errorf("language fields should be escaped:\n%s", note)
+	}
+}
+
+func TestGenerate_Extended(t *testing.T) {
+	gen := &Generator{cfg: &config.NoteConfig{}}
+	summary := &summarizer.Summary{
+		Title:       "Go 1.22 发布说明",
+		OneSentence: "一句话",
+		KeyPoints:   []string{"要点"},
+		Sections: []summarizer.Section{
+			{Heading: "语言变化", Points: []string{"循环变量按迭代创建"}, Subsections: []summarizer.Section{
+				{Heading: "range over int", Points: []string{"for i := range 10"}},
+			}},
+		},
+		Quotes:        []summarizer.Quote{{Text: "Less is\nmore.", Location: "第 2 节"}, {Text: "无位置"}},
+		CodeSnippets:  []summarizer.CodeSnippet{{Language: "md", Code: "```go\nx\n```", Caption: "嵌套代码块"}},
+		Glossary:      []summarizer.GlossaryTerm{{Term: "PGO", Definition: "按剖析结果优化"}},
+		ActionItems:   []string{"升级 go.mod"},
+		OpenQuestions: []string{"对性能的影响?"},
+	}
+
+	note := gen.Generate(summary, "https://go.dev/doc/go1.22", nil)
+	for _, s := range []string{
+		"## 🗂️ 内容结构\n\n### 语言变化\n\n- 循环变量按迭代创建\n\n#### range over int\n\n- for i := range 10\n",
+		"> [!quote] 第 2 节\n> Less is\n> more.\n",
+		"> [!quote]\n> 无位置\n",
+		"**嵌套代码块**\n\n````md\n```go\nx\n```\n````\n",
+		"- **PGO**: 按剖析结果优化\n",
+		"## ✅ 行动项\n\n- [ ] 升级 go.mod\n",
+		"## ❓ 待解决问题\n\n- 对性能的影响?\n",
+	} {
+		if !strings.Contains(note, s) {
+			t.Errorf("note missing %q:\n%s", s, note)
+		}
+	}
+
+	// 没有扩展字段时不输出对应区块
+	if plain := gen.Generate(&summarizer.Summary{Title: "T", OneSentence: "S"}, "https://example.com", nil); strings.Contains(plain, "术语表") {
+		t.Errorf("empty extended fields rendered:\n%s", plain)
+	}
+}
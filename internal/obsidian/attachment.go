@@ -0,0 +1,73 @@
+package obsidian
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/fromsko/krio/internal/config"
+)
+
+// AttachmentStore 附件存储
+// MCP 的 create_note 只支持文本内容,二进制附件直接写入 vault 目录
+type AttachmentStore struct {
+	vaultPath string
+}
+
+// NewAttachmentStore 创建附件存储
+func NewAttachmentStore(cfg *config.ObsidianMCPConfig) (*AttachmentStore, error) {
+	vaultPath := ResolveVaultPath(cfg)
+	if vaultPath == "" {
+		return nil, fmt.Errorf("未配置 vault 路径 (obsidian_mcp.vault_path 或 args 中的 --vault)")
+	}
+
+	info, err := os.Stat(vaultPath)
+	if err != nil {
+		return nil, fmt.Errorf("vault 路径不可用: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("vault 路径不是目录: %s", vaultPath)
+	}
+
+	return &AttachmentStore{vaultPath: vaultPath}, nil
+}
+
+// ResolveVaultPath 获取 vault 根目录: vault_path 优先,否则取 args 中 --vault 的参数
+func ResolveVaultPath(cfg *config.ObsidianMCPConfig) string {
+	if cfg.VaultPath != "" {
+		return cfg.VaultPath
+	}
+	for i, arg := range cfg.Args {
+		if arg == "--vault" && i+1 < len(cfg.Args) {
+			return cfg.Args[i+1]
+		}
+	}
+	return ""
+}
+
+// Save 保存附件,返回 vault 内的相对路径 (用于 ![[...]] 嵌入)
+// 同名文件已存在时直接复用 (文件名基于内容哈希,内容必然相同)
+// folder 可能来自工具请求,解析后不在 vault 内的路径 (如 ../..) 返回错误
+func (s *AttachmentStore) Save(folder, filename string, data []byte) (string, error) {
+	relPath := path.Join(folder, filename)
+	fullPath := filepath.Join(s.vaultPath, filepath.FromSlash(relPath))
+	if rel, err := filepath.Rel(s.vaultPath, fullPath); err != nil ||
+		rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("附件路径不在 vault 内: %s", relPath)
+	}
+
+	if _, err := os.Stat(fullPath); err == nil {
+		return relPath, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return "", fmt.Errorf("创建附件目录失败: %w", err)
+	}
+	if err := os.WriteFile(fullPath, data, 0644); err != nil {
+		return "", fmt.Errorf("写入附件失败: %w", err)
+	}
+
+	return relPath, nil
+}
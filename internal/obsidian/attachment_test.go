@@ -0,0 +1,53 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAttachmentStore_Save(t *testing.T) {
+	root := t.TempDir()
+	vault := filepath.Join(root, "vault")
+	if err := os.Mkdir(vault, 0755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	store := &AttachmentStore{vaultPath: vault}
+
+	tests := []struct {
+		name    string
+		folder  string
+		want    string
+		wantErr bool
+	}{
+		{"默认目录", "Inbox/attachments", "Inbox/attachments/a.html", false},
+		{"目录内的 ..", "Inbox/../Archive", "Archive/a.html", false},
+		{"跳出 vault", "../..", "", true},
+		{"跳出 vault 后再进入", "../vault2", "", true},
+		{"多级跳出", "Inbox/../../outside", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Save(tt.folder, "a.html", []byte("<html></html>"))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Save() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Save() = %q, %v, want %q", got, err, tt.want)
+			}
+			if _, err := os.Stat(filepath.Join(vault, filepath.FromSlash(got))); err != nil {
+				t.Errorf("attachment not written: %v", err)
+			}
+		})
+	}
+
+	// vault 之外不应写入任何文件
+	entries, _ := os.ReadDir(root)
+	if len(entries) != 1 {
+		t.Errorf("files written outside vault: %v", entries)
+	}
+}
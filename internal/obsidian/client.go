@@ -55,23 +55,12 @@ func NewClient(ctx context.Context, cfg *config.ObsidianMCPConfig) (*Client, err
 func (c *Client) SaveNote(ctx context.Context, content, filename, folder string) (string, error) {
 	log := logger.Get()
 
-	// 获取工具列表
-	tools := c.toolSet.Tools(ctx)
-
 	// 查找 create_note 工具
-	var createNoteTool tool.Tool
-	for _, t := range tools {
-		decl := t.Declaration()
-		if decl != nil && decl.Name == "create_note" {
-			createNoteTool = t
-			log.Info("找到 create_note 工具")
-			break
-		}
-	}
-
+	createNoteTool := c.findTool(ctx, "create_note")
 	if createNoteTool == nil {
 		return "", fmt.Errorf("未找到 create_note 工具")
 	}
+	log.Info("找到 create_note 工具")
 
 	// 准备参数 - 尝试不同的参数组合
 	// 根据错误信息 "path" 参数缺失,尝试使用 "path" 作为参数名
@@ -131,6 +120,62 @@ func (c *Client) SaveNote(ctx context.Context, content, filename, folder string)
 	return "", fmt.Errorf("工具不支持调用")
 }
 
+// 更新笔记的候选工具名 (不同 MCP 服务器命名不同)
+var updateNoteTools = []string{"update_note", "edit_note", "write_note"}
+
+// UpdateNote 覆盖更新已有笔记,返回笔记路径
+// MCP 服务器需提供 update_note/edit_note/write_note 之一,否则返回错误
+func (c *Client) UpdateNote(ctx context.Context, content, notePath string) (string, error) {
+	log := logger.Get()
+
+	updateTool := c.findTool(ctx, updateNoteTools...)
+	if updateTool == nil {
+		return "", fmt.Errorf("MCP 服务器不支持更新笔记 (未找到 %s 工具)", strings.Join(updateNoteTools, "/"))
+	}
+
+	callable, ok := updateTool.(tool.CallableTool)
+	if !ok {
+		return "", fmt.Errorf("工具不支持调用")
+	}
+
+	jsonArgs, err := json.Marshal(map[string]interface{}{
+		"path":    notePath,
+		"content": content,
+	})
+	if err != nil {
+		return "", fmt.Errorf("序列化参数失败: %w", err)
+	}
+
+	log.Info("更新 Obsidian 笔记",
+		zap.String("tool", updateTool.Declaration().Name),
+		zap.String("path", notePath),
+		zap.Int("content_length", len(content)),
+	)
+
+	if _, err := callable.Call(ctx, jsonArgs); err != nil {
+		return "", fmt.Errorf("调用 %s 工具失败: %w", updateTool.Declaration().Name, err)
+	}
+
+	return notePath, nil
+}
+
+// findTool 按名称顺序查找第一个可用的 MCP 工具
+func (c *Client) findTool(ctx context.Context, names ...string) tool.Tool {
+	available := make(map[string]tool.Tool)
+	for _, t := range c.toolSet.Tools(ctx) {
+		if decl := t.Declaration(); decl != nil {
+			available[decl.Name] = t
+		}
+	}
+
+	for _, name := range names {
+		if t, ok := available[name]; ok {
+			return t
+		}
+	}
+	return nil
+}
+
 // Close 关闭客户端
 func (c *Client) Close() error {
 	log := logger.Get()
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"io"
+
+	"github.com/fromsko/krio/internal/policy"
+)
+
+// Rejection 被过滤规则拒绝的 URL
+type Rejection struct {
+	URL    string
+	Reason error
+}
+
+// FilteredParser 按过滤规则筛选解析结果的解析器
+type FilteredParser struct {
+	parser   Parser
+	filter   *policy.Filter
+	Rejected []Rejection // 最近一次解析中被拒绝的 URL
+}
+
+// NewFilteredParser 创建带过滤规则的解析器
+func NewFilteredParser(p Parser, filter *policy.Filter) *FilteredParser {
+	return &FilteredParser{parser: p, filter: filter}
+}
+
+// Parse 解析 URL 并丢弃被过滤规则拒绝的条目,拒绝原因记录在 Rejected 中
+func (p *FilteredParser) Parse(r io.Reader) ([]string, error) {
+	urls, err := p.parser.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Rejected = nil
+	kept := urls[:0]
+	for _, u := range urls {
+		if err := p.filter.CheckURL(u); err != nil {
+			p.Rejected = append(p.Rejected, Rejection{URL: u, Reason: err})
+			continue
+		}
+		kept = append(kept, u)
+	}
+	return kept, nil
+}
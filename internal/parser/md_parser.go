@@ -43,13 +43,20 @@ func (p *MdParser) Parse(r io.Reader) ([]string, error) {
 }
 
 // cleanURL 清理 URL
-// 移除尾部可能的标点符号和 Markdown 语法字符
+// 移除尾部的标点符号和 Markdown 语法字符,其余保持原样 (规范化只用于去重和缓存键,不改变实际抓取的地址)
+// 只有未配对的右括号才会被移除,保留 https://en.wikipedia.org/wiki/Go_(language) 这类地址
 func cleanURL(rawURL string) string {
-	// 移除尾部的标点符号
-	url := strings.TrimRight(rawURL, ".,;:!?()[]{}\"'`")
-
-	// 移除尾部的 / ) 等字符
-	url = strings.TrimRight(url, "/)")
+	url := rawURL
+	for {
+		trimmed := strings.TrimRight(url, ".,;:!?[]{}\"'`")
+		if strings.HasSuffix(trimmed, ")") && strings.Count(trimmed, "(") < strings.Count(trimmed, ")") {
+			trimmed = strings.TrimSuffix(trimmed, ")")
+		}
+		if trimmed == url {
+			break
+		}
+		url = trimmed
+	}
 
 	return url
 }
@@ -1,8 +1,12 @@
 package parser
 
 import (
+	"errors"
 	"strings"
 	"testing"
+
+	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/internal/policy"
 )
 
 func TestTxtParser_Parse(t *testing.T) {
@@ -11,7 +15,7 @@ https://example.com
 
 https://test.com
 # 另一个注释
-https://github.com/test
+https://github.com/test/#readme
 `
 
 	parser := NewTxtParser()
@@ -27,7 +31,7 @@ https://github.com/test
 	expected := []string{
 		"https://example.com",
 		"https://test.com",
-		"https://github.com/test",
+		"https://github.com/test/#readme", // 保持原始写法
 	}
 
 	for i, url := range urls {
@@ -60,10 +64,10 @@ func TestMdParser_Parse(t *testing.T) {
 		t.Logf("Parsed URLs: %v", urls)
 	}
 
-	// 验证包含的 URL (注意: cleanURL 会移除尾部斜杠)
+	// 验证包含的 URL (保持原始写法,不移除尾部斜杠)
 	expectedURLs := []string{
-		"https://react.dev",      // 尾部斜杠被移除
-		"https://vuejs.org",      // 尾部斜杠被移除
+		"https://react.dev/",
+		"https://vuejs.org/",
 		"https://example.com/resources",
 		"https://test.com/page",
 	}
@@ -78,3 +82,45 @@ func TestMdParser_Parse(t *testing.T) {
 		}
 	}
 }
+
+func TestCleanURL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"Markdown 链接右括号", "https://react.dev/)", "https://react.dev/"},
+		{"句末标点", "https://example.com/a.", "https://example.com/a"},
+		{"配对括号", "https://en.wikipedia.org/wiki/Go_(language)", "https://en.wikipedia.org/wiki/Go_(language)"},
+		{"配对括号后跟右括号", "https://en.wikipedia.org/wiki/Go_(language))", "https://en.wikipedia.org/wiki/Go_(language)"},
+		{"保留查询参数和片段", "https://example.com/a/?utm_source=x&b=2#/route", "https://example.com/a/?utm_source=x&b=2#/route"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanURL(tt.in); got != tt.want {
+				t.Errorf("cleanURL(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilteredParser_Parse(t *testing.T) {
+	filter, err := policy.New(&config.FilterConfig{DenyDomains: []string{"spam.com"}})
+	if err != nil {
+		t.Fatalf("policy.New failed: %v", err)
+	}
+
+	p := NewFilteredParser(NewTxtParser(), filter)
+	urls, err := p.Parse(strings.NewReader("https://example.com\nhttps://spam.com/a\nhttps://test.com\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(urls) != 2 || urls[0] != "https://example.com" || urls[1] != "https://test.com" {
+		t.Errorf("urls = %v", urls)
+	}
+	if len(p.Rejected) != 1 || p.Rejected[0].URL != "https://spam.com/a" || !errors.Is(p.Rejected[0].Reason, policy.ErrRejected) {
+		t.Errorf("Rejected = %+v", p.Rejected)
+	}
+}
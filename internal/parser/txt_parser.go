@@ -35,6 +35,7 @@ func (p *TxtParser) Parse(r io.Reader) ([]string, error) {
 		}
 
 		// 验证是否为有效 URL
+		// 保留原始写法用于抓取,规范化只用于去重和缓存键
 		if isValidURL(line) {
 			urls = append(urls, line)
 		}
@@ -0,0 +1,180 @@
+// Package policy 提供 URL 黑白名单和内容过滤规则,供解析器和抓取器共用
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/internal/lang"
+)
+
+// ErrRejected 被过滤规则拒绝
+var ErrRejected = errors.New("已拒绝")
+
+// rejectf 生成带拒绝原因的错误
+func rejectf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrRejected, fmt.Sprintf(format, args...))
+}
+
+// pattern 单条匹配规则
+type pattern struct {
+	raw string
+	re  *regexp.Regexp
+}
+
+// Filter 过滤规则
+// nil 或零值 Filter 不拒绝任何 URL 和内容
+type Filter struct {
+	allowDomains []pattern
+	denyDomains  []pattern
+	allowPaths   []pattern
+	denyPaths    []pattern
+
+	minContentLength int
+	languages        map[string]bool
+
+	invalid error // 规则无效的原因,非空时拒绝所有 URL 和内容
+}
+
+// New 按配置编译过滤规则
+func New(cfg *config.FilterConfig) (*Filter, error) {
+	f := &Filter{minContentLength: cfg.MinContentLength}
+
+	var err error
+	if f.allowDomains, err = compile(cfg.AllowDomains, true); err != nil {
+		return nil, err
+	}
+	if f.denyDomains, err = compile(cfg.DenyDomains, true); err != nil {
+		return nil, err
+	}
+	if f.allowPaths, err = compile(cfg.AllowPaths, false); err != nil {
+		return nil, err
+	}
+	if f.denyPaths, err = compile(cfg.DenyPaths, false); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Languages) > 0 {
+		f.languages = make(map[string]bool)
+		for _, l := range cfg.Languages {
+			f.languages[lang.Base(l)] = true
+		}
+	}
+	return f, nil
+}
+
+// compile 编译规则列表
+// re: 开头为正则,否则为通配符;域名通配符不含 * 时同时匹配子域名
+func compile(raws []string, domain bool) ([]pattern, error) {
+	patterns := make([]pattern, 0, len(raws))
+	for _, raw := range raws {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		var expr string
+		switch rest, isRegexp := strings.CutPrefix(raw, "re:"); {
+		case isRegexp:
+			expr = rest
+		case domain && !strings.Contains(raw, "*"):
+			expr = `^(.+\.)?` + regexp.QuoteMeta(strings.TrimPrefix(strings.ToLower(raw), ".")) + "$"
+		default:
+			glob := raw
+			if domain {
+				glob = strings.ToLower(glob)
+			}
+			parts := strings.Split(glob, "*")
+			for i, part := range parts {
+				parts[i] = regexp.QuoteMeta(part)
+			}
+			expr = "^" + strings.Join(parts, ".*") + "$"
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("过滤规则无效: %s: %w", raw, err)
+		}
+		patterns = append(patterns, pattern{raw: raw, re: re})
+	}
+	return patterns, nil
+}
+
+// match 返回第一条匹配的规则
+func match(patterns []pattern, s string) (string, bool) {
+	for _, p := range patterns {
+		if p.re.MatchString(s) {
+			return p.raw, true
+		}
+	}
+	return "", false
+}
+
+// DenyAll 返回拒绝所有 URL 和内容的 Filter,用于过滤规则无效时按拒绝处理
+func DenyAll(reason error) *Filter {
+	return &Filter{invalid: reason}
+}
+
+// CheckURL 检查 URL 是否允许抓取,拒绝时返回包含原因的 ErrRejected
+func (f *Filter) CheckURL(rawURL string) error {
+	if f == nil {
+		return nil
+	}
+	if f.invalid != nil {
+		return rejectf("过滤规则无效: %v", f.invalid)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rejectf("URL 格式错误")
+	}
+	host := strings.ToLower(u.Hostname())
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	if rule, ok := match(f.denyDomains, host); ok {
+		return rejectf("域名 %s 命中黑名单 %s", host, rule)
+	}
+	if len(f.allowDomains) > 0 {
+		if _, ok := match(f.allowDomains, host); !ok {
+			return rejectf("域名 %s 不在白名单中", host)
+		}
+	}
+	if rule, ok := match(f.denyPaths, path); ok {
+		return rejectf("路径 %s 命中黑名单 %s", path, rule)
+	}
+	if len(f.allowPaths) > 0 {
+		if _, ok := match(f.allowPaths, path); !ok {
+			return rejectf("路径 %s 不在白名单中", path)
+		}
+	}
+	return nil
+}
+
+// CheckContent 检查正文长度和语言,拒绝时返回包含原因的 ErrRejected
+// 无法识别语言时不按语言拒绝
+func (f *Filter) CheckContent(content string) error {
+	if f == nil {
+		return nil
+	}
+	if f.invalid != nil {
+		return rejectf("过滤规则无效: %v", f.invalid)
+	}
+	if f.minContentLength > 0 {
+		if n := utf8.RuneCountInString(strings.TrimSpace(content)); n < f.minContentLength {
+			return rejectf("正文过短 (%d 字符,最少 %d),可能是登录页或占位页", n, f.minContentLength)
+		}
+	}
+	if len(f.languages) > 0 {
+		if detected := lang.Detect(content); detected != "" && !f.languages[detected] {
+			return rejectf("正文语言 %s 不在允许列表中", detected)
+		}
+	}
+	return nil
+}
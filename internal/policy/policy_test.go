@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fromsko/krio/internal/config"
+)
+
+func TestFilter_CheckURL(t *testing.T) {
+	filter, err := New(&config.FilterConfig{
+		AllowDomains: []string{"example.com", "*.dev", "re:^docs\\.[a-z]+\\.org$"},
+		DenyDomains:  []string{"ads.example.com"},
+		DenyPaths:    []string{"/login*", "re:^/tag/"},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	tests := []struct {
+		url    string
+		reason string // 为空表示允许
+	}{
+		{"https://example.com/post/1", ""},
+		{"https://blog.example.com/post/1", ""},
+		{"https://go.dev/doc", ""},
+		{"https://docs.python.org/3/", ""},
+		{"https://ads.example.com/x", "命中黑名单 ads.example.com"},
+		{"https://notexample.com/", "不在白名单中"},
+		{"https://example.com/login?next=/", "命中黑名单 /login*"},
+		{"https://example.com/tag/go", "命中黑名单 re:^/tag/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := filter.CheckURL(tt.url)
+			if tt.reason == "" {
+				if err != nil {
+					t.Errorf("CheckURL() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrRejected) || !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("CheckURL() = %v, want rejection containing %q", err, tt.reason)
+			}
+		})
+	}
+}
+
+func TestFilter_CheckContent(t *testing.T) {
+	filter, err := New(&config.FilterConfig{MinContentLength: 20, Languages: []string{"zh-CN"}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		reason  string
+	}{
+		{"中文正文", "Go 语言是 Google 开发的一种静态强类型、编译型、并发型的编程语言。", ""},
+		{"登录页", "请登录后查看", "正文过短"},
+		{"英文正文", "Go is a statically typed, compiled programming language designed at Google.", "语言 en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := filter.CheckContent(tt.content)
+			if tt.reason == "" {
+				if err != nil {
+					t.Errorf("CheckContent() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrRejected) || !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("CheckContent() = %v, want rejection containing %q", err, tt.reason)
+			}
+		})
+	}
+
+	// nil Filter 不做过滤
+	var none *Filter
+	if none.CheckURL("https://any.com") != nil || none.CheckContent("") != nil {
+		t.Error("nil Filter should accept everything")
+	}
+
+	if _, err := New(&config.FilterConfig{DenyPaths: []string{"re:("}}); err == nil {
+		t.Error("New() should fail on invalid regexp")
+	}
+
+	// 规则无效时拒绝一切
+	deny := DenyAll(errors.New("bad rule"))
+	if err := deny.CheckURL("https://any.com"); !errors.Is(err, ErrRejected) {
+		t.Errorf("DenyAll().CheckURL() = %v, want ErrRejected", err)
+	}
+	if err := deny.CheckContent("正文"); !errors.Is(err, ErrRejected) {
+		t.Errorf("DenyAll().CheckContent() = %v, want ErrRejected", err)
+	}
+}
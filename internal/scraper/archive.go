@@ -0,0 +1,77 @@
+package scraper
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// archiveNoise 存档时从正文中移除的元素
+const archiveNoise = "script, style, nav, header, footer, iframe, noscript, aside, form, button"
+
+// ArticleMarkdown 获取清理后的正文 Markdown (用于原文存档)
+// 通用 HTML 页面从原始 HTML 重新提取正文并转换; 站点提取器和非 HTML 内容直接使用提取结果
+func ArticleMarkdown(page *WebPage) (string, error) {
+	if page.RawHTML == "" || page.Site != "" {
+		return page.Content, nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page.RawHTML))
+	if err != nil {
+		return "", fmt.Errorf("解析 HTML 失败: %w", err)
+	}
+
+	root := doc.Find("article").First()
+	if root.Length() == 0 {
+		root = doc.Find("main").First()
+	}
+	if root.Length() == 0 {
+		root = doc.Find("body")
+	}
+	root.Find(archiveNoise).Remove()
+
+	articleHTML, err := goquery.OuterHtml(root)
+	if err != nil {
+		return "", fmt.Errorf("读取正文 HTML 失败: %w", err)
+	}
+
+	// 使用页面地址补全相对链接和图片地址
+	markdown, err := htmltomarkdown.ConvertString(articleHTML, converter.WithDomain(page.URL))
+	if err != nil {
+		return "", fmt.Errorf("转换 Markdown 失败: %w", err)
+	}
+
+	return strings.TrimSpace(markdown), nil
+}
+
+// HTMLSnapshot 生成页面 HTML 快照
+// 移除脚本避免离线打开时执行,并注入 <base> 使相对资源链接仍指向原站;
+// 图片和样式表不内联,离线或原站失效后快照只剩文本和结构
+func HTMLSnapshot(page *WebPage) (string, error) {
+	if page.RawHTML == "" {
+		return "", fmt.Errorf("非 HTML 页面,无法生成快照")
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page.RawHTML))
+	if err != nil {
+		return "", fmt.Errorf("解析 HTML 失败: %w", err)
+	}
+
+	doc.Find("script, noscript, iframe").Remove()
+	doc.Find("base").Remove()
+
+	head := doc.Find("head")
+	head.PrependHtml(fmt.Sprintf(`<meta charset="utf-8"><base href="%s">`, html.EscapeString(page.URL)))
+	head.PrependHtml(fmt.Sprintf("<!-- Krio 快照: %s (抓取时间 %s) -->",
+		strings.ReplaceAll(page.URL, "--", "%2D%2D"), page.FetchedAt.Format("2006-01-02 15:04:05")))
+
+	snapshot, err := doc.Html()
+	if err != nil {
+		return "", fmt.Errorf("生成快照失败: %w", err)
+	}
+	return snapshot, nil
+}
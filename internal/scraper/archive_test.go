@@ -0,0 +1,60 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+)
+
+const archiveHTML = `<html><head><title>Post</title><script>track()</script></head><body>
+<nav>Home | About</nav>
+<article>
+  <h1>Hello</h1>
+  <p>Read the <a href="/docs">docs</a>.</p>
+  <pre><code>go run .</code></pre>
+  <aside>Related posts</aside>
+</article>
+<footer>Copyright</footer>
+</body></html>`
+
+func TestArticleMarkdown(t *testing.T) {
+	page := &WebPage{URL: "https://example.com/posts/1", RawHTML: archiveHTML}
+
+	markdown, err := ArticleMarkdown(page)
+	if err != nil {
+		t.Fatalf("ArticleMarkdown failed: %v", err)
+	}
+
+	assertContains(t, markdown,
+		[]string{"# Hello", "[docs](https://example.com/docs)", "```\ngo run .\n```"},
+		[]string{"Home | About", "Related posts", "Copyright", "track()"},
+	)
+
+	// 站点提取器的结果直接使用提取内容
+	page.Site = "github"
+	page.Content = "README"
+	if markdown, _ := ArticleMarkdown(page); markdown != "README" {
+		t.Errorf("ArticleMarkdown() = %q, want extracted content", markdown)
+	}
+}
+
+func TestHTMLSnapshot(t *testing.T) {
+	page := &WebPage{
+		URL:       "https://example.com/posts/1",
+		RawHTML:   archiveHTML,
+		FetchedAt: time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC),
+	}
+
+	snapshot, err := HTMLSnapshot(page)
+	if err != nil {
+		t.Fatalf("HTMLSnapshot failed: %v", err)
+	}
+
+	assertContains(t, snapshot,
+		[]string{`<base href="https://example.com/posts/1"/>`, "2026-01-05 12:00:00", "Related posts"},
+		[]string{"<script>", "track()"},
+	)
+
+	if _, err := HTMLSnapshot(&WebPage{Content: "plain"}); err == nil {
+		t.Error("HTMLSnapshot() expected error for non-HTML page")
+	}
+}
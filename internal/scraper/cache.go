@@ -0,0 +1,546 @@
+package scraper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fromsko/krio/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Cache 缓存结构
+// 设置了持久化目录时,每个条目保存为目录下的一个 JSON 文件,进程重启后仍然有效
+// 超出条目数或字节数上限时按最近最少使用 (LRU) 淘汰
+type Cache struct {
+	mu    sync.RWMutex
+	items map[string]*CacheEntry
+	dir   string // 持久化目录,为空时仅内存缓存
+	bytes int64  // 当前缓存条目总字节数 (估算)
+
+	touched map[string]struct{} // 访问时间已更新但尚未写入持久化文件的条目
+
+	maxEntries int   // 最大条目数,0 表示不限制
+	maxBytes   int64 // 最大字节数,0 表示不限制
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+	expired   atomic.Int64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// CacheEntry 缓存条目
+// 过期条目不会立即删除,其 ETag/Last-Modified 用于条件请求,由后台清理任务在保留期后删除
+type CacheEntry struct {
+	Key        string    `json:"key"`
+	Page       *WebPage  `json:"page"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	AccessedAt time.Time `json:"accessed_at"`         // 最近访问时间,用于 LRU 淘汰
+	Size       int64     `json:"size"`                // 条目大小 (估算字节数)
+	NotePath   string    `json:"note_path,omitempty"` // 已生成笔记的路径
+	NoteHash   string    `json:"note_hash,omitempty"` // 生成笔记时的内容哈希
+	NoteOpts   string    `json:"note_opts,omitempty"` // 生成笔记时的选项摘要 (风格、语言、模型等)
+}
+
+// CacheStats 缓存统计
+// 命中/未命中/淘汰/过期计数在持久化缓存中跨进程累计
+type CacheStats struct {
+	Entries    int   `json:"entries"`
+	Bytes      int64 `json:"bytes"`
+	MaxEntries int   `json:"max_entries"`
+	MaxBytes   int64 `json:"max_bytes"`
+	Hits       int64 `json:"hits"`
+	Misses     int64 `json:"misses"`
+	Evictions  int64 `json:"evictions"`
+	Expired    int64 `json:"expired"`
+}
+
+// statsFile 计数器持久化文件名
+const statsFile = "_stats.json"
+
+// Expired 是否已过期
+func (e *CacheEntry) Expired() bool {
+	return time.Now().After(e.ExpiresAt)
+}
+
+// NewCache 创建缓存
+func NewCache() *Cache {
+	return &Cache{
+		items:   make(map[string]*CacheEntry),
+		touched: make(map[string]struct{}),
+		stop:    make(chan struct{}),
+	}
+}
+
+// NewPersistentCache 创建持久化缓存,并加载目录中已有的条目
+func NewPersistentCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建缓存目录失败: %w", err)
+	}
+
+	c := &Cache{
+		items:   make(map[string]*CacheEntry),
+		touched: make(map[string]struct{}),
+		dir:     dir,
+		stop:    make(chan struct{}),
+	}
+	c.loadStats()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("读取缓存目录失败: %w", err)
+	}
+
+	for _, file := range files {
+		if filepath.Base(file) == statsFile {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var entry CacheEntry
+		if err := json.Unmarshal(data, &entry); err != nil || entry.Key == "" || entry.Page == nil {
+			// 损坏的缓存文件直接丢弃
+			logger.Get().Debug("丢弃损坏的缓存文件", zap.String("file", file))
+			_ = os.Remove(file)
+			continue
+		}
+		if entry.Size == 0 {
+			entry.Size = pageSize(entry.Page)
+		}
+		// 文件名与键不一致 (如手工放入) 时改为规范文件名,保证删除时能找到文件
+		if want := c.entryFile(entry.Key); file != want {
+			if err := os.Rename(file, want); err != nil {
+				continue
+			}
+		}
+		if old, exists := c.items[entry.Key]; exists {
+			c.bytes -= old.Size
+		}
+		c.items[entry.Key] = &entry
+		c.bytes += entry.Size
+	}
+
+	return c, nil
+}
+
+// SetLimits 设置条目数和字节数上限 (0 表示不限制),超出时立即淘汰
+func (c *Cache) SetLimits(maxEntries int, maxBytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxEntries = maxEntries
+	c.maxBytes = maxBytes
+	c.evict("")
+}
+
+// Get 获取未过期的缓存
+func (c *Cache) Get(key string) (*WebPage, bool) {
+	entry, found := c.Lookup(key)
+	if !found || entry.Expired() {
+		return nil, false
+	}
+	return entry.Page, true
+}
+
+// Lookup 查找缓存条目 (包括已过期的) 并更新访问时间,返回副本
+// 未过期时计为命中,否则计为未命中;访问时间只更新内存,由后台清理任务或 Close 批量写入持久化文件
+func (c *Cache) Lookup(key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.items[key]
+	if !exists || entry.Expired() {
+		c.misses.Add(1)
+	} else {
+		c.hits.Add(1)
+	}
+	if !exists {
+		return CacheEntry{}, false
+	}
+
+	entry.AccessedAt = time.Now()
+	if c.dir != "" {
+		c.touched[key] = struct{}{}
+	}
+	return *entry, true
+}
+
+// Entry 获取缓存条目 (包括已过期的),返回副本
+// 不计入命中统计,也不更新访问时间
+func (c *Cache) Entry(key string) (CacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.items[key]
+	if !exists {
+		return CacheEntry{}, false
+	}
+	return *entry, true
+}
+
+// Set 设置缓存,保留已有的笔记记录
+func (c *Cache) Set(key string, page *WebPage, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entry := &CacheEntry{
+		Key:        key,
+		Page:       page,
+		ExpiresAt:  now.Add(ttl),
+		AccessedAt: now,
+		Size:       pageSize(page),
+	}
+	if old, exists := c.items[key]; exists {
+		entry.NotePath = old.NotePath
+		entry.NoteHash = old.NoteHash
+		entry.NoteOpts = old.NoteOpts
+		c.bytes -= old.Size
+	}
+
+	c.items[key] = entry
+	c.bytes += entry.Size
+	c.persist(entry)
+	c.evict(key)
+}
+
+// Entries 返回所有缓存条目的副本,按 URL 排序
+func (c *Cache) Entries() []CacheEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entries := make([]CacheEntry, 0, len(c.items))
+	for _, entry := range c.items {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// Delete 删除指定条目,返回是否存在
+func (c *Cache) Delete(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.items[key]; !exists {
+		return false
+	}
+	c.delete(key)
+	return true
+}
+
+// DeleteFunc 删除满足条件的条目,返回删除数量
+func (c *Cache) DeleteFunc(match func(CacheEntry) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, entry := range c.items {
+		if match(*entry) {
+			c.delete(key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Refresh 延长缓存有效期 (条件请求确认内容未变化时)
+func (c *Cache) Refresh(key string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.items[key]
+	if !exists {
+		return
+	}
+	entry.ExpiresAt = time.Now().Add(ttl)
+	entry.AccessedAt = time.Now()
+	c.persist(entry)
+}
+
+// MarkNoted 记录已为该内容生成的笔记及生成时的选项摘要
+func (c *Cache) MarkNoted(key, notePath, contentHash, options string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.items[key]
+	if !exists {
+		return
+	}
+	entry.NotePath = notePath
+	entry.NoteHash = contentHash
+	entry.NoteOpts = options
+	c.persist(entry)
+}
+
+// Clear 清空缓存
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.items {
+		c.remove(key)
+	}
+	c.items = make(map[string]*CacheEntry)
+	c.touched = make(map[string]struct{})
+	c.bytes = 0
+}
+
+// Size 返回缓存大小
+func (c *Cache) Size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// Stats 返回缓存统计
+func (c *Cache) Stats() CacheStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return CacheStats{
+		Entries:    len(c.items),
+		Bytes:      c.bytes,
+		MaxEntries: c.maxEntries,
+		MaxBytes:   c.maxBytes,
+		Hits:       c.hits.Load(),
+		Misses:     c.misses.Load(),
+		Evictions:  c.evictions.Load(),
+		Expired:    c.expired.Load(),
+	}
+}
+
+// Prune 删除过期时间超过 retention 的条目,返回删除数量
+// retention 为 0 时删除所有已过期条目
+func (c *Cache) Prune(retention time.Duration) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deadline := time.Now().Add(-retention)
+	removed := 0
+	for key, entry := range c.items {
+		if entry.ExpiresAt.Before(deadline) {
+			c.delete(key)
+			removed++
+		}
+	}
+	c.expired.Add(int64(removed))
+	return removed
+}
+
+// StartJanitor 启动后台清理任务,每隔 interval 删除过期超过 retention 的条目
+// 调用 Close 停止
+func (c *Cache) StartJanitor(interval, retention time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if removed := c.Prune(retention); removed > 0 {
+					logger.Get().Debug("清理过期缓存", zap.Int("removed", removed))
+				}
+				c.flushAccess()
+				c.saveStats()
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close 停止后台清理任务,写入未保存的访问时间和统计计数
+func (c *Cache) Close() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+	c.flushAccess()
+	c.saveStats()
+}
+
+// flushAccess 将 Lookup 更新的访问时间写入持久化文件,重启后 LRU 顺序不变
+func (c *Cache) flushAccess() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.touched {
+		if entry, exists := c.items[key]; exists {
+			c.persist(entry)
+		}
+	}
+	clear(c.touched)
+}
+
+// evict 超出上限时按 LRU 淘汰条目 (调用方需持有锁)
+// keep 为刚写入的条目,不会被淘汰
+func (c *Cache) evict(keep string) {
+	for c.overLimit() {
+		var oldest *CacheEntry
+		for key, entry := range c.items {
+			if key == keep {
+				continue
+			}
+			if oldest == nil || entry.AccessedAt.Before(oldest.AccessedAt) {
+				oldest = entry
+			}
+		}
+		if oldest == nil {
+			return
+		}
+
+		logger.Get().Debug("淘汰缓存条目", zap.String("key", oldest.Key))
+		c.delete(oldest.Key)
+		c.evictions.Add(1)
+	}
+}
+
+// overLimit 是否超出上限 (调用方需持有锁)
+func (c *Cache) overLimit() bool {
+	return (c.maxEntries > 0 && len(c.items) > c.maxEntries) ||
+		(c.maxBytes > 0 && c.bytes > c.maxBytes)
+}
+
+// delete 删除条目及其持久化文件 (调用方需持有锁)
+func (c *Cache) delete(key string) {
+	entry, exists := c.items[key]
+	if !exists {
+		return
+	}
+	c.bytes -= entry.Size
+	delete(c.items, key)
+	delete(c.touched, key)
+	c.remove(key)
+}
+
+// pageSize 估算页面占用的字节数
+func pageSize(page *WebPage) int64 {
+	if page == nil {
+		return 0
+	}
+	size := len(page.URL) + len(page.Title) + len(page.Content) + len(page.RawHTML) + len(page.LeadImage)
+	for _, img := range page.Images {
+		size += len(img)
+	}
+	for k, v := range page.Metadata {
+		size += len(k) + len(v)
+	}
+	return int64(size)
+}
+
+// cacheCounters 持久化的统计计数
+type cacheCounters struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+	Expired   int64 `json:"expired"`
+}
+
+// loadStats 加载持久化的统计计数
+func (c *Cache) loadStats() {
+	data, err := os.ReadFile(filepath.Join(c.dir, statsFile))
+	if err != nil {
+		return
+	}
+	var counters cacheCounters
+	if err := json.Unmarshal(data, &counters); err != nil {
+		return
+	}
+	c.hits.Store(counters.Hits)
+	c.misses.Store(counters.Misses)
+	c.evictions.Store(counters.Evictions)
+	c.expired.Store(counters.Expired)
+}
+
+// saveStats 保存统计计数
+func (c *Cache) saveStats() {
+	if c.dir == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := json.Marshal(cacheCounters{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Expired:   c.expired.Load(),
+	})
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, statsFile), data, 0644); err != nil {
+		logger.Get().Warn("保存缓存统计失败", zap.Error(err))
+	}
+}
+
+// entryFile 缓存条目对应的文件路径
+func (c *Cache) entryFile(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// persist 写入持久化文件 (调用方需持有锁)
+// 先写临时文件再重命名,避免中断时留下半个文件
+func (c *Cache) persist(entry *CacheEntry) {
+	if c.dir == "" {
+		return
+	}
+	delete(c.touched, entry.Key)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		logger.Get().Warn("序列化缓存失败", zap.String("key", entry.Key), zap.Error(err))
+		return
+	}
+
+	file := c.entryFile(entry.Key)
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		logger.Get().Warn("写入缓存失败", zap.String("key", entry.Key), zap.Error(err))
+		return
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		_ = os.Remove(tmp)
+		logger.Get().Warn("写入缓存失败", zap.String("key", entry.Key), zap.Error(err))
+	}
+}
+
+// remove 删除持久化文件 (调用方需持有锁)
+func (c *Cache) remove(key string) {
+	if c.dir == "" {
+		return
+	}
+	if err := os.Remove(c.entryFile(key)); err != nil && !os.IsNotExist(err) {
+		logger.Get().Warn("删除缓存文件失败", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// DefaultCacheDir 默认缓存根目录: <用户缓存目录>/krio
+// 网页缓存位于其下的 pages 子目录,总结缓存位于 summaries 子目录
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil || strings.TrimSpace(dir) == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "krio")
+}
@@ -0,0 +1,116 @@
+package scraper
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxArchiveFileSize 导入时单个缓存文件的大小上限
+const maxArchiveFileSize = 64 << 20
+
+// ExportCache 将缓存根目录下的网页缓存和总结缓存打包为 tar.gz
+// 返回写入的文件数
+func ExportCache(root string, w io.Writer) (int, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	count := 0
+	for _, sub := range []string{cachePagesDir, cacheSummariesDir} {
+		files, err := filepath.Glob(filepath.Join(root, sub, "*.json"))
+		if err != nil {
+			return count, err
+		}
+		for _, file := range files {
+			if filepath.Base(file) == statsFile {
+				continue
+			}
+			if err := addTarFile(tw, file, path.Join(sub, filepath.Base(file))); err != nil {
+				return count, fmt.Errorf("打包 %s 失败: %w", file, err)
+			}
+			count++
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return count, err
+	}
+	return count, gz.Close()
+}
+
+// addTarFile 向 tar 写入单个文件
+func addTarFile(tw *tar.Writer, file, name string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: info.ModTime(),
+	}); err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// ImportCache 从 tar.gz 导入缓存到缓存根目录,已存在的同名条目会被覆盖
+// 只接受 pages/ 和 summaries/ 下的 JSON 文件,其余内容忽略
+// 返回导入的文件数
+func ImportCache(root string, r io.Reader) (int, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, fmt.Errorf("读取压缩包失败: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	count := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("读取压缩包失败: %w", err)
+		}
+
+		dir, name := path.Split(path.Clean(hdr.Name))
+		dir = strings.TrimSuffix(dir, "/")
+		if hdr.Typeflag != tar.TypeReg || (dir != cachePagesDir && dir != cacheSummariesDir) ||
+			path.Ext(name) != ".json" || name == statsFile || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if hdr.Size > maxArchiveFileSize {
+			return count, fmt.Errorf("缓存文件过大: %s", hdr.Name)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tr, maxArchiveFileSize))
+		if err != nil {
+			return count, fmt.Errorf("读取 %s 失败: %w", hdr.Name, err)
+		}
+
+		target := filepath.Join(root, dir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return count, fmt.Errorf("创建缓存目录失败: %w", err)
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return count, fmt.Errorf("写入 %s 失败: %w", target, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
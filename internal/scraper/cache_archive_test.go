@@ -0,0 +1,82 @@
+package scraper
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExportImportCache(t *testing.T) {
+	src := t.TempDir()
+	cache, err := NewPersistentCache(filepath.Join(src, cachePagesDir))
+	if err != nil {
+		t.Fatalf("NewPersistentCache failed: %v", err)
+	}
+	cache.Set("https://example.com/a", &WebPage{URL: "https://example.com/a", Content: "正文"}, time.Hour)
+	cache.Close()
+
+	if err := os.MkdirAll(filepath.Join(src, cacheSummariesDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, cacheSummariesDir, "abc.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	count, err := ExportCache(src, &buf)
+	if err != nil {
+		t.Fatalf("ExportCache() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("ExportCache() count = %d, want 2 (统计文件不导出)", count)
+	}
+
+	dst := t.TempDir()
+	count, err = ImportCache(dst, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ImportCache() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("ImportCache() count = %d, want 2", count)
+	}
+
+	imported, err := NewPersistentCache(filepath.Join(dst, cachePagesDir))
+	if err != nil {
+		t.Fatalf("NewPersistentCache failed: %v", err)
+	}
+	page, ok := imported.Get("https://example.com/a")
+	if !ok || page.Content != "正文" {
+		t.Errorf("导入后 Get() = %+v, %v", page, ok)
+	}
+	if _, err := os.Stat(filepath.Join(dst, cacheSummariesDir, "abc.json")); err != nil {
+		t.Errorf("总结缓存未导入: %v", err)
+	}
+}
+
+func TestImportCache_RejectsUnsafePaths(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"../evil.json", "pages/../../evil.json", "other/x.json", "pages/x.sh"} {
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 2, Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte("{}"))
+	}
+	tw.Close()
+	gz.Close()
+
+	dst := t.TempDir()
+	count, err := ImportCache(filepath.Join(dst, "root"), &buf)
+	if err != nil {
+		t.Fatalf("ImportCache() error = %v", err)
+	}
+	if count != 0 {
+		t.Errorf("ImportCache() count = %d, want 0", count)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "evil.json")); !os.IsNotExist(err) {
+		t.Error("不应写入缓存根目录之外")
+	}
+}
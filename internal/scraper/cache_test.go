@@ -0,0 +1,194 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fromsko/krio/internal/config"
+)
+
+func TestPersistentCache_Reload(t *testing.T) {
+	dir := t.TempDir()
+
+	cache, err := NewPersistentCache(dir)
+	if err != nil {
+		t.Fatalf("NewPersistentCache failed: %v", err)
+	}
+
+	page := &WebPage{URL: "https://example.com/a", Title: "A", Content: "hello", ETag: `"v1"`, ContentHash: "h1"}
+	cache.Set(page.URL, page, time.Hour)
+	cache.MarkNoted(page.URL, "Inbox/a.md", "h1", "style=tldr")
+
+	// 重新加载后条目和笔记记录仍然存在
+	reloaded, err := NewPersistentCache(dir)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+
+	got, ok := reloaded.Get(page.URL)
+	if !ok || got.Title != "A" || got.ETag != `"v1"` {
+		t.Fatalf("Get() = %+v, %v", got, ok)
+	}
+
+	entry, _ := reloaded.Entry(page.URL)
+	if entry.NotePath != "Inbox/a.md" || entry.NoteHash != "h1" || entry.NoteOpts != "style=tldr" {
+		t.Errorf("note record lost: %+v", entry)
+	}
+
+	// 访问时间只更新内存,Close 时写入持久化文件
+	accessed := entry.AccessedAt
+	if again, _ := NewPersistentCache(dir); sameTime(again, page.URL, accessed) {
+		t.Errorf("Lookup() should not persist AccessedAt before Close")
+	}
+	reloaded.Close()
+	if again, _ := NewPersistentCache(dir); !sameTime(again, page.URL, accessed) {
+		t.Errorf("Close() should persist AccessedAt %v", accessed)
+	}
+
+	// 重新抓取后保留笔记记录
+	reloaded.Set(page.URL, &WebPage{URL: page.URL, ContentHash: "h2"}, time.Hour)
+	if entry, _ := reloaded.Entry(page.URL); entry.NotePath != "Inbox/a.md" {
+		t.Errorf("Set() should keep note record, got %+v", entry)
+	}
+
+	reloaded.Clear()
+	if again, _ := NewPersistentCache(dir); again.Size() != 0 {
+		t.Errorf("Clear() should remove persisted entries, size = %d", again.Size())
+	}
+}
+
+// sameTime 缓存条目的访问时间是否为 want
+func sameTime(c *Cache, key string, want time.Time) bool {
+	entry, ok := c.Entry(key)
+	return ok && entry.AccessedAt.Equal(want)
+}
+
+func TestCache_ExpiredEntry(t *testing.T) {
+	cache := NewCache()
+	cache.Set("k", &WebPage{URL: "k"}, -time.Second)
+
+	if _, ok := cache.Get("k"); ok {
+		t.Error("Get() should miss expired entry")
+	}
+	entry, ok := cache.Entry("k")
+	if !ok || !entry.Expired() {
+		t.Errorf("Entry() should return expired entry, got %+v, %v", entry, ok)
+	}
+
+	cache.Refresh("k", time.Hour)
+	if _, ok := cache.Get("k"); !ok {
+		t.Error("Get() should hit after Refresh()")
+	}
+}
+
+func TestCache_LRUEviction(t *testing.T) {
+	cache := NewCache()
+	cache.SetLimits(2, 0)
+
+	base := time.Now().Add(-time.Hour)
+	cache.Set("a", &WebPage{URL: "a"}, time.Hour)
+	cache.Set("b", &WebPage{URL: "b"}, time.Hour)
+	cache.items["a"].AccessedAt = base
+	cache.items["b"].AccessedAt = base.Add(time.Minute)
+	cache.Get("a") // a 成为最近使用
+	cache.Set("c", &WebPage{URL: "c"}, time.Hour)
+
+	if _, ok := cache.Entry("b"); ok {
+		t.Error("最近最少使用的 b 应被淘汰")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Entry(key); !ok {
+			t.Errorf("%s 不应被淘汰", key)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Evictions != 1 || stats.Hits != 1 {
+		t.Errorf("Stats() = %+v, want entries=2 evictions=1 hits=1", stats)
+	}
+}
+
+func TestCache_ByteLimit(t *testing.T) {
+	cache := NewCache()
+	cache.SetLimits(0, 100)
+
+	cache.Set("a", &WebPage{Content: strings.Repeat("x", 60)}, time.Hour)
+	cache.Set("b", &WebPage{Content: strings.Repeat("y", 60)}, time.Hour)
+
+	stats := cache.Stats()
+	if stats.Entries != 1 || stats.Bytes > 100 {
+		t.Errorf("Stats() = %+v, want 1 entry within 100 bytes", stats)
+	}
+	if _, ok := cache.Entry("b"); !ok {
+		t.Error("刚写入的条目不应被淘汰")
+	}
+}
+
+func TestCache_Prune(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewPersistentCache(dir)
+	if err != nil {
+		t.Fatalf("NewPersistentCache failed: %v", err)
+	}
+
+	cache.Set("fresh", &WebPage{URL: "fresh"}, time.Hour)
+	cache.Set("stale", &WebPage{URL: "stale"}, -time.Minute)
+	cache.Set("old", &WebPage{URL: "old"}, -48*time.Hour)
+
+	// 保留期内的过期条目仍保留,用于条件请求
+	if removed := cache.Prune(24 * time.Hour); removed != 1 {
+		t.Errorf("Prune(24h) removed = %d, want 1", removed)
+	}
+	if removed := cache.Prune(0); removed != 1 {
+		t.Errorf("Prune(0) removed = %d, want 1", removed)
+	}
+	cache.Get("fresh")
+	cache.Get("missing")
+	cache.Close()
+
+	// 计数器跨进程累计
+	reloaded, err := NewPersistentCache(dir)
+	if err != nil {
+		t.Fatalf("NewPersistentCache failed: %v", err)
+	}
+	stats := reloaded.Stats()
+	if stats.Entries != 1 || stats.Expired != 2 || stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Stats() = %+v, want entries=1 expired=2 hits=1 misses=1", stats)
+	}
+}
+
+func TestFetchOnce_Conditional(t *testing.T) {
+	const etag = `"abc"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 05 Jan 2026 12:00:00 GMT")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, "<html><head><title>Cond</title></head><body><p>body</p></body></html>")
+	}))
+	defer server.Close()
+
+	// fetchOnce 不做 SSRF 校验,可以直接访问本地测试服务器
+	fetcher := NewFetcher(&config.ScraperConfig{UserAgent: "test-agent", Timeout: 5})
+
+	page, err := fetcher.fetchOnce(server.URL, Validators{})
+	if err != nil {
+		t.Fatalf("fetchOnce failed: %v", err)
+	}
+	if page.ETag != etag || page.LastModified == "" || page.ContentHash == "" {
+		t.Errorf("validators not recorded: %+v", page)
+	}
+
+	_, err = fetcher.fetchOnce(server.URL, Validators{ETag: page.ETag})
+	if !errors.Is(err, ErrNotModified) {
+		t.Errorf("fetchOnce() error = %v, want ErrNotModified", err)
+	}
+}
@@ -0,0 +1,90 @@
+package scraper
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fromsko/krio/internal/classify"
+)
+
+// videoEmbedHosts 视频网站内嵌播放器地址特征
+var videoEmbedHosts = []string{"youtube.com/embed", "youtube-nocookie.com", "player.vimeo.com", "player.bilibili.com"}
+
+// collectSignals 收集页面类型判断所需的 HTML 特征
+// 需在提取器修改 DOM 之前调用
+func collectSignals(doc *goquery.Selection) classify.Signals {
+	s := classify.Signals{
+		OGType:      metaContent(doc, "og:type"),
+		SchemaTypes: schemaTypes(doc),
+		CodeBlocks:  doc.Find("pre").Length(),
+		HasVideo:    doc.Find("video").Length() > 0,
+	}
+	if !s.HasVideo {
+		doc.Find("iframe[src]").EachWithBreak(func(_ int, iframe *goquery.Selection) bool {
+			src := strings.ToLower(iframe.AttrOr("src", ""))
+			for _, host := range videoEmbedHosts {
+				if strings.Contains(src, host) {
+					s.HasVideo = true
+					return false
+				}
+			}
+			return true
+		})
+	}
+	return s
+}
+
+// schemaTypes 收集 JSON-LD 和 microdata 顶层实体声明的 schema.org 类型
+func schemaTypes(doc *goquery.Selection) []string {
+	var types []string
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		var data any
+		if err := json.Unmarshal([]byte(s.Text()), &data); err == nil {
+			types = appendJSONTypes(types, data)
+		}
+	})
+	doc.Find("[itemtype]:not([itemprop])").Each(func(_ int, s *goquery.Selection) {
+		for _, itemType := range strings.Fields(s.AttrOr("itemtype", "")) {
+			types = append(types, path.Base(itemType))
+		}
+	})
+	return types
+}
+
+// appendJSONTypes 收集 JSON-LD 顶层实体 (含 @graph) 的 @type
+// 不进入作者、发布者等嵌套属性,避免把文章中提到的产品或视频当作页面类型
+func appendJSONTypes(types []string, data any) []string {
+	switch v := data.(type) {
+	case map[string]any:
+		switch t := v["@type"].(type) {
+		case string:
+			types = append(types, t)
+		case []any:
+			for _, item := range t {
+				if s, ok := item.(string); ok {
+					types = append(types, s)
+				}
+			}
+		}
+		if graph, ok := v["@graph"]; ok {
+			types = appendJSONTypes(types, graph)
+		}
+	case []any:
+		for _, child := range v {
+			types = appendJSONTypes(types, child)
+		}
+	}
+	return types
+}
+
+// classifyPage 根据已收集的 HTML 特征和提取结果判断页面类型
+func classifyPage(page *WebPage, signals classify.Signals) {
+	signals.URL = page.URL
+	signals.Title = page.Title
+	signals.Content = page.Content
+	signals.MIMEType = page.ContentType
+	signals.Site = page.Site
+	page.PageType = classify.Classify(signals)
+}
@@ -0,0 +1,37 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestCollectSignals(t *testing.T) {
+	html := `<html><head>
+<meta property="og:type" content="article">
+<script type="application/ld+json">{"@graph":[{"@type":"WebPage"},{"@type":["NewsArticle","Article"],"about":{"@type":"Product"}}]}</script>
+</head><body>
+<article itemscope itemtype="https://schema.org/BlogPosting">
+  <div itemprop="author" itemscope itemtype="https://schema.org/Person"></div>
+  <pre><code>go run .</code></pre><pre>go test ./...</pre>
+  <iframe src="https://www.youtube.com/embed/abc"></iframe>
+</article>
+</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("解析 HTML 失败: %v", err)
+	}
+
+	s := collectSignals(doc.Selection)
+	if s.OGType != "article" {
+		t.Errorf("OGType = %q", s.OGType)
+	}
+	// 只收集顶层实体,不含 about 中的 Product 和作者 Person
+	if got := strings.Join(s.SchemaTypes, ","); got != "WebPage,NewsArticle,Article,BlogPosting" {
+		t.Errorf("SchemaTypes = %s", got)
+	}
+	if s.CodeBlocks != 2 || !s.HasVideo {
+		t.Errorf("CodeBlocks = %d, HasVideo = %v", s.CodeBlocks, s.HasVideo)
+	}
+}
@@ -0,0 +1,118 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrUnsupportedContentType 不支持的内容类型 (图片、压缩包等二进制资源)
+var ErrUnsupportedContentType = errors.New("不支持的内容类型")
+
+// Extractor 非 HTML 内容提取器
+type Extractor interface {
+	// Name 提取器名称
+	Name() string
+	// Match 根据内容类型、URL 和响应体判断是否由该提取器处理
+	Match(contentType string, u *url.URL, body []byte) bool
+	// Extract 提取内容并填充到 page
+	Extract(page *WebPage, body []byte) error
+}
+
+// ExtractorRegistry 提取器注册表,按注册顺序匹配
+type ExtractorRegistry struct {
+	extractors []Extractor
+}
+
+// NewExtractorRegistry 创建包含内置提取器的注册表
+func NewExtractorRegistry() *ExtractorRegistry {
+	return &ExtractorRegistry{
+		extractors: []Extractor{
+			&pdfExtractor{},
+			&jsonExtractor{},
+			&markdownExtractor{},
+			&codeExtractor{},
+			&textExtractor{},
+		},
+	}
+}
+
+// Register 注册提取器
+// 后注册的提取器优先匹配,便于覆盖内置行为
+func (r *ExtractorRegistry) Register(e Extractor) {
+	r.extractors = append([]Extractor{e}, r.extractors...)
+}
+
+// Lookup 查找匹配的提取器
+// HTML 内容返回 nil, nil (由 colly 的 HTML 回调处理)
+func (r *ExtractorRegistry) Lookup(contentType string, u *url.URL, body []byte) (Extractor, error) {
+	mediaType := parseMediaType(contentType)
+
+	for _, e := range r.extractors {
+		if e.Match(mediaType, u, body) {
+			return e, nil
+		}
+	}
+
+	if isHTMLType(mediaType) {
+		return nil, nil
+	}
+
+	if mediaType == "" {
+		mediaType = "未知"
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, mediaType)
+}
+
+// parseMediaType 解析 Content-Type 中的媒体类型 (去除 charset 等参数)
+func parseMediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+	return mediaType
+}
+
+// isHTMLType 判断是否为 HTML 类型
+// 未声明类型时视为 HTML,保持原有行为
+func isHTMLType(mediaType string) bool {
+	return mediaType == "" || strings.Contains(mediaType, "html")
+}
+
+// urlExt 获取 URL 路径的扩展名 (小写)
+func urlExt(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return strings.ToLower(path.Ext(u.Path))
+}
+
+// urlFilename 获取 URL 路径中的文件名
+func urlFilename(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	name := path.Base(strings.TrimSuffix(u.Path, "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	return name
+}
+
+// isTextual 判断媒体类型是否为可当作文本读取的类型
+// 部分服务器 (如 raw.githubusercontent.com) 对所有文件返回 text/plain 或 octet-stream
+// HTML/XHTML 不算: 地址以 .md、.go 等结尾的网页 (如 GitHub 的 /blob/ 页面) 仍按网页提取
+func isTextual(mediaType string) bool {
+	if mediaType != "" && isHTMLType(mediaType) {
+		return false
+	}
+	return mediaType == "" ||
+		strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/octet-stream"
+}
@@ -0,0 +1,139 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fromsko/krio/internal/config"
+)
+
+func TestExtractorRegistry_Lookup(t *testing.T) {
+	registry := NewExtractorRegistry()
+
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		want        string // 提取器名称,"" 表示 HTML
+		wantErr     bool
+	}{
+		{"html", "https://example.com/a", "text/html; charset=utf-8", "<html></html>", "", false},
+		{"no content type", "https://example.com/a", "", "<html></html>", "", false},
+		{"pdf", "https://arxiv.org/pdf/1706.03762", "application/pdf", "%PDF-1.5", "pdf", false},
+		{"plain text", "https://example.com/notes.txt", "text/plain", "hello", "text", false},
+		{"raw markdown", "https://raw.githubusercontent.com/a/b/main/README.md", "text/plain; charset=utf-8", "# Title", "markdown", false},
+		{"markdown type", "https://example.com/doc", "text/markdown", "# Title", "markdown", false},
+		{"source code", "https://raw.githubusercontent.com/a/b/main/main.go", "text/plain; charset=utf-8", "package main", "code", false},
+		{"json", "https://api.example.com/openapi", "application/json", "{}", "json", false},
+		{"problem json", "https://api.example.com/err", "application/problem+json", "{}", "json", false},
+		{"GitHub blob json", "https://github.com/a/b/blob/main/package.json", "text/html; charset=utf-8", "<!DOCTYPE html><html></html>", "", false},
+		{"GitHub blob markdown", "https://github.com/a/b/blob/main/README.md", "text/html; charset=utf-8", "<!DOCTYPE html><html></html>", "", false},
+		{"GitHub blob code", "https://github.com/a/b/blob/main/main.go", "text/html; charset=utf-8", "<!DOCTYPE html><html></html>", "", false},
+		{"xhtml markdown url", "https://example.com/doc.md", "application/xhtml+xml", "<html></html>", "", false},
+		{"html txt url", "https://example.com/robots.txt", "text/html", "<html></html>", "", false},
+		{"image", "https://example.com/a.png", "image/png", "\x89PNG", "", true},
+		{"image txt url", "https://example.com/a.txt", "image/png", "\x89PNG", "", true},
+		{"zip", "https://example.com/a.zip", "application/zip", "PK", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := url.Parse(tt.url)
+			e, err := registry.Lookup(tt.contentType, u, []byte(tt.body))
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsupportedContentType) {
+					t.Fatalf("Lookup() error = %v, want ErrUnsupportedContentType", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup() unexpected error: %v", err)
+			}
+
+			got := ""
+			if e != nil {
+				got = e.Name()
+			}
+			if got != tt.want {
+				t.Errorf("Lookup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeExtractor_Extract(t *testing.T) {
+	page := &WebPage{URL: "https://raw.githubusercontent.com/a/b/main/cmd/main.py", ContentType: "text/plain"}
+	body := "print('```')\n"
+
+	if err := (&codeExtractor{}).Extract(page, []byte(body)); err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if page.Title != "main.py" {
+		t.Errorf("Title = %q, want main.py", page.Title)
+	}
+	// 内容含三个反引号时,代码块需要更长的围栏
+	if !strings.HasPrefix(page.Content, "````python\n") || !strings.HasSuffix(page.Content, "\n````") {
+		t.Errorf("unexpected fenced content: %q", page.Content)
+	}
+}
+
+func TestJSONExtractor_Extract(t *testing.T) {
+	page := &WebPage{URL: "https://api.example.com/openapi.json"}
+	body := `{"openapi":"3.0.0","info":{"title":"Pet Store API"}}`
+
+	if err := (&jsonExtractor{}).Extract(page, []byte(body)); err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if page.Title != "Pet Store API" {
+		t.Errorf("Title = %q, want Pet Store API", page.Title)
+	}
+	if !strings.Contains(page.Content, "```json\n{\n  \"openapi\"") {
+		t.Errorf("JSON not pretty printed: %q", page.Content)
+	}
+
+	if err := (&jsonExtractor{}).Extract(&WebPage{}, []byte("{invalid")); err == nil {
+		t.Error("Extract() expected error for invalid JSON")
+	}
+}
+
+func TestMarkdownExtractor_Extract(t *testing.T) {
+	page := &WebPage{URL: "https://raw.githubusercontent.com/a/b/main/README.md"}
+	body := "<!-- badge -->\n# Krio\n\nSome text"
+
+	if err := (&markdownExtractor{}).Extract(page, []byte(body)); err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if page.Title != "Krio" {
+		t.Errorf("Title = %q, want Krio", page.Title)
+	}
+}
+
+func TestFetchOnce_HTMLAtFileURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, "<html><head><title>blob</title></head><body><article><p>"+strings.Repeat("文件内容预览。", 50)+"</p></article></body></html>")
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(&config.ScraperConfig{UserAgent: "test-agent", Timeout: 5 * time.Second})
+	for _, file := range []string{"package.json", "README.md", "main.go"} {
+		t.Run(file, func(t *testing.T) {
+			page, err := fetcher.fetchOnce(server.URL+"/a/b/blob/main/"+file, Validators{})
+			if err != nil {
+				t.Fatalf("fetchOnce() error = %v", err)
+			}
+			if page.ContentType != "text/html" || page.RawHTML == "" {
+				t.Errorf("ContentType = %q, RawHTML empty = %v, want HTML page", page.ContentType, page.RawHTML == "")
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+)
+
+// textExtractor 纯文本提取器
+type textExtractor struct{}
+
+func (e *textExtractor) Name() string { return "text" }
+
+func (e *textExtractor) Match(contentType string, u *url.URL, body []byte) bool {
+	return contentType == "text/plain" || (urlExt(u) == ".txt" && isTextual(contentType))
+}
+
+func (e *textExtractor) Extract(page *WebPage, body []byte) error {
+	if !utf8.Valid(body) {
+		return fmt.Errorf("%w: 非 UTF-8 文本", ErrUnsupportedContentType)
+	}
+
+	page.ContentType = "text/plain"
+	page.Content = strings.TrimSpace(string(body))
+	page.Title = firstLine(page.Content)
+	return nil
+}
+
+// markdownExtractor Markdown 提取器
+type markdownExtractor struct{}
+
+func (e *markdownExtractor) Name() string { return "markdown" }
+
+func (e *markdownExtractor) Match(contentType string, u *url.URL, body []byte) bool {
+	if contentType == "text/markdown" || contentType == "text/x-markdown" {
+		return true
+	}
+	ext := urlExt(u)
+	return (ext == ".md" || ext == ".markdown") && isTextual(contentType)
+}
+
+func (e *markdownExtractor) Extract(page *WebPage, body []byte) error {
+	if !utf8.Valid(body) {
+		return fmt.Errorf("%w: 非 UTF-8 文本", ErrUnsupportedContentType)
+	}
+
+	page.ContentType = "text/markdown"
+	page.Content = strings.TrimSpace(string(body))
+
+	// 优先使用一级标题
+	for _, line := range strings.Split(page.Content, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			page.Title = strings.TrimSpace(strings.TrimPrefix(line, "# "))
+			break
+		}
+	}
+	return nil
+}
+
+// codeLanguages 扩展名到代码语言的映射 (用于代码块标注)
+var codeLanguages = map[string]string{
+	".go":    "go",
+	".py":    "python",
+	".js":    "javascript",
+	".mjs":   "javascript",
+	".ts":    "typescript",
+	".tsx":   "tsx",
+	".jsx":   "jsx",
+	".rs":    "rust",
+	".java":  "java",
+	".kt":    "kotlin",
+	".c":     "c",
+	".h":     "c",
+	".cc":    "cpp",
+	".cpp":   "cpp",
+	".hpp":   "cpp",
+	".cs":    "csharp",
+	".rb":    "ruby",
+	".php":   "php",
+	".swift": "swift",
+	".sh":    "bash",
+	".bash":  "bash",
+	".ps1":   "powershell",
+	".sql":   "sql",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+	".toml":  "toml",
+	".xml":   "xml",
+	".css":   "css",
+	".lua":   "lua",
+	".proto": "protobuf",
+}
+
+// codeMediaTypes 媒体类型到代码语言的映射
+var codeMediaTypes = map[string]string{
+	"application/javascript": "javascript",
+	"text/javascript":        "javascript",
+	"application/x-sh":       "bash",
+	"text/x-python":          "python",
+	"text/x-go":              "go",
+	"text/x-c":               "c",
+	"text/x-java-source":     "java",
+	"application/x-yaml":     "yaml",
+	"application/yaml":       "yaml",
+	"text/yaml":              "yaml",
+	"application/toml":       "toml",
+	"text/css":               "css",
+}
+
+// codeExtractor 源代码提取器,内容包裹在带语言标注的代码块中
+type codeExtractor struct{}
+
+func (e *codeExtractor) Name() string { return "code" }
+
+func (e *codeExtractor) Match(contentType string, u *url.URL, body []byte) bool {
+	if _, ok := codeMediaTypes[contentType]; ok {
+		return true
+	}
+	_, ok := codeLanguages[urlExt(u)]
+	return ok && isTextual(contentType)
+}
+
+func (e *codeExtractor) Extract(page *WebPage, body []byte) error {
+	if !utf8.Valid(body) {
+		return fmt.Errorf("%w: 非 UTF-8 文本", ErrUnsupportedContentType)
+	}
+
+	u, _ := url.Parse(page.URL)
+	lang := detectLanguage(page.ContentType, u, body)
+
+	page.ContentType = "text/x-source"
+	page.Title = urlFilename(u)
+	page.Content = fencedBlock(lang, strings.TrimRight(string(body), "\n"))
+	return nil
+}
+
+// detectLanguage 检测代码语言: 扩展名 > 媒体类型 > shebang
+func detectLanguage(contentType string, u *url.URL, body []byte) string {
+	if lang, ok := codeLanguages[urlExt(u)]; ok {
+		return lang
+	}
+	if lang, ok := codeMediaTypes[parseMediaType(contentType)]; ok {
+		return lang
+	}
+	if bytes.HasPrefix(body, []byte("#!")) {
+		shebang := firstLine(string(body))
+		switch {
+		case strings.Contains(shebang, "python"):
+			return "python"
+		case strings.Contains(shebang, "node"):
+			return "javascript"
+		case strings.Contains(shebang, "sh"):
+			return "bash"
+		}
+	}
+	return ""
+}
+
+// jsonExtractor JSON 提取器 (API 文档、配置等)
+type jsonExtractor struct{}
+
+func (e *jsonExtractor) Name() string { return "json" }
+
+func (e *jsonExtractor) Match(contentType string, u *url.URL, body []byte) bool {
+	if contentType == "application/json" || strings.HasSuffix(contentType, "+json") {
+		return true
+	}
+	return urlExt(u) == ".json" && isTextual(contentType)
+}
+
+func (e *jsonExtractor) Extract(page *WebPage, body []byte) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimSpace(body), "", "  "); err != nil {
+		return fmt.Errorf("解析 JSON 失败: %w", err)
+	}
+
+	u, _ := url.Parse(page.URL)
+	page.ContentType = "application/json"
+	page.Content = fencedBlock("json", buf.String())
+
+	// OpenAPI/Swagger 文档使用 info.title,其他 JSON 尝试顶层 title/name
+	for _, key := range []string{"info.title", "title", "name"} {
+		if title := gjson.GetBytes(body, key); title.Type == gjson.String && title.String() != "" {
+			page.Title = title.String()
+			break
+		}
+	}
+	if page.Title == "" {
+		page.Title = urlFilename(u)
+	}
+	return nil
+}
+
+// fencedBlock 生成 Markdown 代码块,自动避开内容中的反引号
+func fencedBlock(lang, code string) string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fmt.Sprintf("%s%s\n%s\n%s", fence, lang, code, fence)
+}
+
+// firstLine 获取第一行非空文本 (最多 100 个字符)
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if runes := []rune(line); len(runes) > 100 {
+			line = string(runes[:100])
+		}
+		return line
+	}
+	return ""
+}
@@ -1,49 +1,248 @@
 package scraper
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/cookiejar"
 	"net/url"
+	"path"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/fromsko/krio/internal/classify"
 	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/internal/lang"
+	"github.com/fromsko/krio/internal/policy"
+	"github.com/fromsko/krio/internal/urlnorm"
+	"github.com/fromsko/krio/pkg/logger"
 	"github.com/gocolly/colly/v2"
+	"go.uber.org/zap"
 )
 
 // WebPage 网页内容
 type WebPage struct {
-	URL     string
-	Title   string
-	Content string
+	URL         string
+	Canonical   string // 页面声明的规范地址 (link rel=canonical)
+	Title       string
+	Content     string
+	ContentType string // 响应内容类型,如 text/html、application/pdf
+	Language    string // 正文语言 (ISO 639-1,如 zh、en),无法识别时取 html lang 属性
+	PageCount   int    // PDF 页数 (非 PDF 为 0)
+	Site        string // 站点提取器名称,通用提取时为空
+	PageType    string // 页面类型,如 tutorial、paper、news (见 classify 包)
+	Metadata    map[string]string
+	LeadImage   string    // 头图地址 (og:image)
+	Images      []string  // 正文图片地址
+	RawHTML     string    // 原始 HTML (用于原文存档,非 HTML 内容为空)
+	FetchedAt   time.Time // 抓取时间
+
+	ETag         string // 响应的 ETag,用于条件请求
+	LastModified string // 响应的 Last-Modified,用于条件请求
+	ContentHash  string // 提取内容的 sha256,用于变化检测
+
+	ArchivedFrom string    // 原链接失效时使用的存档快照地址
+	ArchivedAt   time.Time // 存档快照时间
+
+	Partial       bool   // 正文可能不完整 (登录墙/付费墙)
+	PartialReason string // 判定为不完整的原因
+
+	PageURLs []string // 分页文章拼接的各页地址 (含首页,未拼接时为空)
+	nextPage string   // 下一页地址 (仅在启用分页拼接时识别)
 }
 
+// SourceURL 笔记来源地址: 优先使用页面声明的规范地址,均做规范化处理
+func (p *WebPage) SourceURL() string {
+	if p.Canonical != "" {
+		return urlnorm.Normalize(p.Canonical)
+	}
+	return urlnorm.Normalize(p.URL)
+}
+
+// ErrNotModified 条件请求返回 304,内容未变化
+var ErrNotModified = errors.New("内容未变化")
+
+// ErrResponseTooLarge 响应体超过大小上限,已中止下载
+var ErrResponseTooLarge = errors.New("响应过大")
+
+// ErrTooManyRedirects 重定向次数超过上限
+var ErrTooManyRedirects = errors.New("重定向次数过多")
+
+// Validators 条件请求验证器
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// Empty 是否没有可用的验证器
+func (v Validators) Empty() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// setMetadata 设置元数据,忽略空值
+func (p *WebPage) setMetadata(key, value string) {
+	if value == "" {
+		return
+	}
+	if p.Metadata == nil {
+		p.Metadata = make(map[string]string)
+	}
+	p.Metadata[key] = value
+}
+
+// 内容长度上限 (避免 token 过多)
+// PDF 通常较长,交给总结器分块处理,因此上限更宽松
+const (
+	maxContentLength    = 50000
+	maxPDFContentLength = 300000
+)
+
+// 请求默认值 (配置为 0 时使用)
+const (
+	defaultRequestTimeout  = 15 * time.Second
+	defaultMaxResponseSize = 20 << 20
+	defaultMaxRedirects    = 10
+)
+
 // Fetcher 网页抓取器
 type Fetcher struct {
-	cfg *config.ScraperConfig
+	cfg        *config.ScraperConfig
+	extractors *ExtractorRegistry
+	sites      *SiteRegistry
+	transport  *http.Transport  // 代理设置
+	jar        http.CookieJar   // cookies.txt 和按域名配置的 Cookie
+	filter     *policy.Filter   // URL 黑白名单和内容过滤
+	onExtract  func(url string) // 收到响应、开始提取正文时回调 (用于显示进度)
 }
 
 // NewFetcher 创建抓取器
+// 代理或 Cookie 配置无效时记录警告并忽略该项;过滤规则无效时拒绝所有 URL,而不是放开过滤
 func NewFetcher(cfg *config.ScraperConfig) *Fetcher {
-	return &Fetcher{cfg: cfg}
+	log := logger.Get()
+
+	transport, err := newTransport(&cfg.Proxy)
+	if err != nil {
+		log.Warn("代理配置无效,不使用代理", zap.String("proxy", redactURL(cfg.Proxy.URL)), zap.Error(err))
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	} else if cfg.Proxy.URL != "" {
+		log.Debug("使用代理", zap.String("proxy", redactURL(cfg.Proxy.URL)), zap.Strings("no_proxy", cfg.Proxy.NoProxy))
+	}
+
+	jar, err := newCookieJar(cfg)
+	if err != nil {
+		log.Warn("加载 Cookie 失败", zap.Error(err))
+		jar, _ = cookiejar.New(nil)
+	}
+
+	filter, err := policy.New(&cfg.Filter)
+	if err != nil {
+		log.Error("过滤规则无效,拒绝所有 URL", zap.Error(err))
+		filter = policy.DenyAll(err)
+	}
+
+	return &Fetcher{
+		cfg:        cfg,
+		extractors: NewExtractorRegistry(),
+		sites:      NewSiteRegistry(),
+		transport:  transport,
+		jar:        jar,
+		filter:     filter,
+	}
+}
+
+// Extractors 获取内容提取器注册表,可用于注册自定义提取器
+func (f *Fetcher) Extractors() *ExtractorRegistry {
+	return f.extractors
+}
+
+// SetExtractHook 设置收到响应、开始提取正文时的回调,url 为本次请求的地址
+// 只在请求的地址本身收到响应时调用,后续分页和存档快照不会触发
+// 需在开始抓取前设置;回调可能在多个抓取协程中并发调用
+func (f *Fetcher) SetExtractHook(fn func(url string)) {
+	f.onExtract = fn
+}
+
+// Sites 获取站点提取器注册表,可用于注册自定义站点提取器
+func (f *Fetcher) Sites() *SiteRegistry {
+	return f.sites
 }
 
 // Fetch 抓取网页内容
 func (f *Fetcher) Fetch(urlStr string) (*WebPage, error) {
+	return f.FetchIfModified(urlStr, Validators{})
+}
+
+// FetchIfModified 带验证器的条件抓取,服务器返回 304 时返回 ErrNotModified
+// URL 或正文被过滤规则拒绝时返回 policy.ErrRejected
+func (f *Fetcher) FetchIfModified(urlStr string, validators Validators) (*WebPage, error) {
 	// 验证 URL
 	if err := f.validateURL(urlStr); err != nil {
 		return nil, fmt.Errorf("URL 验证失败: %w", err)
 	}
+	if err := f.filter.CheckURL(urlStr); err != nil {
+		return nil, err
+	}
 
+	page, err := f.fetchWithRetry(urlStr, validators)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.CheckContent(page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// CheckURL 按过滤规则检查 URL,拒绝时返回 policy.ErrRejected
+func (f *Fetcher) CheckURL(urlStr string) error {
+	return f.filter.CheckURL(urlStr)
+}
+
+// CheckContent 按过滤规则检查正文,拒绝时返回 policy.ErrRejected
+// 检测到付费墙且配置为 fail 时返回 ErrPaywalled
+func (f *Fetcher) CheckContent(page *WebPage) error {
+	if page.Partial && f.paywallAction() == PaywallFail {
+		return fmt.Errorf("%w (%s)", ErrPaywalled, page.PartialReason)
+	}
+	return f.filter.CheckContent(page.Content)
+}
+
+// fetchWithRetry 抓取并在失败时重试,链接失效时回退到存档快照
+func (f *Fetcher) fetchWithRetry(urlStr string, validators Validators) (*WebPage, error) {
 	var page *WebPage
 	var fetchErr error
 
 	// 重试逻辑
 	for i := 0; i <= f.cfg.MaxRetries; i++ {
-		page, fetchErr = f.fetchOnce(urlStr)
+		page, fetchErr = f.fetchURL(urlStr, validators, f.onExtract)
 		if fetchErr == nil {
+			if page.nextPage != "" && !page.truncated() {
+				f.stitchPages(page)
+			}
+			return page, nil
+		}
+
+		// 链接已失效时尝试存档快照
+		if errors.Is(fetchErr, ErrDeadLink) {
+			if !f.cfg.ArchiveFallback.Enabled {
+				return nil, fetchErr
+			}
+			page, err := f.fetchArchived(urlStr)
+			if err != nil {
+				return nil, fmt.Errorf("%w (存档回退失败: %v)", fetchErr, err)
+			}
 			return page, nil
 		}
 
+		// 内容未变化、不支持的内容类型或超出限制,重试也无意义
+		if errors.Is(fetchErr, ErrNotModified) || errors.Is(fetchErr, ErrUnsupportedContentType) ||
+			errors.Is(fetchErr, ErrResponseTooLarge) || errors.Is(fetchErr, ErrTooManyRedirects) {
+			return nil, fetchErr
+		}
+
 		if i < f.cfg.MaxRetries {
 			time.Sleep(f.cfg.RetryDelay)
 		}
@@ -52,61 +251,165 @@ func (f *Fetcher) Fetch(urlStr string) (*WebPage, error) {
 	return nil, fmt.Errorf("抓取失败(已重试 %d 次): %w", f.cfg.MaxRetries, fetchErr)
 }
 
-// fetchOnce 单次抓取
-func (f *Fetcher) fetchOnce(urlStr string) (*WebPage, error) {
+// fetchOnce 单次抓取,不触发 onExtract (用于分页、存档快照等附加请求)
+func (f *Fetcher) fetchOnce(urlStr string, validators Validators) (*WebPage, error) {
+	return f.fetchURL(urlStr, validators, nil)
+}
+
+// fetchURL 单次抓取,收到响应开始提取正文时调用 onExtract (可为空)
+func (f *Fetcher) fetchURL(urlStr string, validators Validators, onExtract func(url string)) (*WebPage, error) {
+	maxSize := f.maxResponseSize()
 	c := colly.NewCollector(
 		colly.UserAgent(f.cfg.UserAgent),
 		colly.MaxDepth(1),
 		colly.Async(false),
+		// 多读 1 字节,用于判断是否超出上限
+		colly.MaxBodySize(int(maxSize+1)),
 		// debug.Debugger(&debug.LogDebugger{}), // 调试时启用
 	)
 
-	// 设置超时
-	c.SetRequestTimeout(time.Duration(f.cfg.Timeout) * time.Second)
+	// 设置超时、代理和 Cookie
+	c.SetRequestTimeout(f.requestTimeout())
+	c.WithTransport(f.transport)
+	c.SetCookieJar(f.jar)
+	c.SetRedirectHandler(f.checkRedirect)
 
 	page := &WebPage{}
-	var errorMsg string
+	var requestErr error
+	var extractErr error
+	var sizeErr error
+	var notModified bool
+	var htmlLang string
+	var signals classify.Signals
+
+	// 按 Content-Length 预先检查,超出上限时不下载响应体
+	c.OnResponseHeaders(func(r *colly.Response) {
+		if length := r.Headers.Get("Content-Length"); length != "" {
+			if n, err := strconv.ParseInt(length, 10, 64); err == nil && n > maxSize {
+				sizeErr = fmt.Errorf("%w: Content-Length %d 字节,上限 %d 字节", ErrResponseTooLarge, n, maxSize)
+				r.Request.Abort()
+			}
+		}
+	})
 
-	// 抓取标题
-	c.OnHTML("title", func(e *colly.HTMLElement) {
-		page.Title = strings.TrimSpace(e.Text)
+	// 按域名的请求头和条件请求头
+	c.OnRequest(func(r *colly.Request) {
+		f.applyDomainConfig(*r.Headers, r.URL)
+		if validators.ETag != "" {
+			r.Headers.Set("If-None-Match", validators.ETag)
+		}
+		if validators.LastModified != "" {
+			r.Headers.Set("If-Modified-Since", validators.LastModified)
+		}
 	})
 
-	// 如果没有 title 标签,尝试 h1
-	c.OnHTML("h1", func(e *colly.HTMLElement) {
-		if page.Title == "" {
-			page.Title = strings.TrimSpace(e.Text)
+	// HTML 内容: 优先使用站点专用提取器,失败时回退到通用提取
+	c.OnHTML("html", func(e *colly.HTMLElement) {
+		if sizeErr != nil {
+			return
+		}
+		// 在提取器修改 DOM 前收集图片地址和规范地址
+		collectImages(page, e.Request.URL, e.DOM)
+		page.Canonical = canonicalURL(e.Request.URL, e.DOM)
+		htmlLang = e.Attr("lang")
+		signals = collectSignals(e.DOM)
+		if f.cfg.Pagination.Enabled {
+			page.nextPage = nextPageURL(e.Request.URL, e.DOM)
+		}
+		if f.paywallAction() != PaywallIgnore {
+			if reason := detectPaywallMarkup(e.DOM, f.cfg.Paywall.Markers); reason != "" {
+				page.Partial, page.PartialReason = true, reason
+			}
 		}
+
+		if site := f.sites.Lookup(e.Request.URL); site != nil {
+			err := site.Extract(page, e.DOM.Clone())
+			if err == nil {
+				return
+			}
+			logger.Get().Warn("站点提取器失败,回退到通用提取",
+				zap.String("site", site.Name()),
+				zap.String("url", urlStr),
+				zap.Error(err),
+			)
+			page.Site = ""
+			page.Metadata = nil
+		}
+
+		extractGenericHTML(page, e.DOM)
 	})
 
-	// 抓取主要内容
-	c.OnHTML("body", func(e *colly.HTMLElement) {
-		// 移除不需要的元素
-		e.ForEach("script, style, nav, header, footer, iframe, noscript", func(i int, el *colly.HTMLElement) {
-			_ = el.DOM.Remove()
-		})
+	// 非 HTML 内容按类型分发给提取器
+	c.OnResponse(func(r *colly.Response) {
+		// 未声明 Content-Length 时,读取过程中超出上限即中止
+		if int64(len(r.Body)) > maxSize {
+			sizeErr = fmt.Errorf("%w: 超过 %d 字节", ErrResponseTooLarge, maxSize)
+			return
+		}
+		if onExtract != nil {
+			onExtract(urlStr)
+		}
 
-		// 提取文本内容
-		page.Content = strings.TrimSpace(e.Text)
+		contentType := r.Headers.Get("Content-Type")
+		page.ContentType = parseMediaType(contentType)
+		page.ETag = r.Headers.Get("ETag")
+		page.LastModified = r.Headers.Get("Last-Modified")
+
+		extractor, err := f.extractors.Lookup(contentType, r.Request.URL, r.Body)
+		if err != nil {
+			extractErr = err
+			return
+		}
+		if extractor == nil {
+			page.RawHTML = string(r.Body)
+			return
+		}
+
+		if err := extractor.Extract(page, r.Body); err != nil {
+			extractErr = fmt.Errorf("%s 提取失败: %w", extractor.Name(), err)
+		}
 	})
 
 	// 错误处理
 	c.OnError(func(r *colly.Response, err error) {
-		errorMsg = fmt.Sprintf("请求失败: %s", err)
+		if r != nil && r.StatusCode == http.StatusNotModified {
+			notModified = true
+			return
+		}
+		statusCode := 0
+		if r != nil {
+			statusCode = r.StatusCode
+		}
+		if isDeadLink(statusCode, err) {
+			requestErr = fmt.Errorf("%w: %w", ErrDeadLink, err)
+			return
+		}
+		requestErr = fmt.Errorf("请求失败: %w", err)
 	})
 
 	// 设置 URL
 	page.URL = urlStr
+	page.FetchedAt = time.Now()
 
 	// 开始抓取
-	if err := c.Visit(urlStr); err != nil {
+	// OnError 中已记录的错误优先返回 (带有类型信息)
+	if err := c.Visit(urlStr); err != nil && !notModified && sizeErr == nil && requestErr == nil {
 		return nil, err
 	}
 
 	c.Wait()
 
-	if errorMsg != "" {
-		return nil, fmt.Errorf("%s", errorMsg)
+	if notModified {
+		return nil, ErrNotModified
+	}
+	if sizeErr != nil {
+		return nil, sizeErr
+	}
+	if requestErr != nil {
+		return nil, requestErr
+	}
+	if extractErr != nil {
+		return nil, extractErr
 	}
 
 	// 验证内容
@@ -114,14 +417,93 @@ func (f *Fetcher) fetchOnce(urlStr string) (*WebPage, error) {
 		return nil, fmt.Errorf("未获取到内容")
 	}
 
-	// 限制内容长度 (避免 token 过多)
-	if len(page.Content) > 50000 {
-		page.Content = page.Content[:50000] + "\n\n...(内容过长,已截断)"
+	page.finishContent()
+	page.Language = lang.Detect(page.Content)
+	if page.Language == "" && lang.Valid(htmlLang) {
+		page.Language = lang.Base(htmlLang)
+	}
+	classifyPage(page, signals)
+
+	// 通用提取的 HTML 页面: 正文很短但页面很大时视为不完整
+	if isHTMLType(page.ContentType) && page.Site == "" && !page.Partial && f.paywallAction() != PaywallIgnore {
+		if reason := detectPaywallWeight(page.Content, len(page.RawHTML)); reason != "" {
+			page.Partial, page.PartialReason = true, reason
+		}
+	}
+
+	// 非 HTML 内容没有标题时,使用文件名
+	if page.Title == "" && !isHTMLType(page.ContentType) {
+		page.Title = path.Base(strings.TrimSuffix(urlStr, "/"))
 	}
 
 	return page, nil
 }
 
+// truncatedMark 内容超长截断后追加的标记
+const truncatedMark = "\n\n...(内容过长,已截断)"
+
+// finishContent 限制内容长度 (避免 token 过多) 并计算内容哈希 (用于变化检测)
+func (p *WebPage) finishContent() {
+	limit := maxContentLength
+	if p.ContentType == contentTypePDF {
+		limit = maxPDFContentLength
+	}
+	if len(p.Content) > limit {
+		p.Content = p.Content[:limit] + truncatedMark
+	}
+
+	sum := sha256.Sum256([]byte(p.Content))
+	p.ContentHash = hex.EncodeToString(sum[:])
+}
+
+// truncated 内容是否已被截断
+func (p *WebPage) truncated() bool {
+	return strings.HasSuffix(p.Content, truncatedMark)
+}
+
+// requestTimeout 请求超时
+// 兼容旧配置中不带单位的整数 (如 timeout: 15),按秒解析
+func (f *Fetcher) requestTimeout() time.Duration {
+	switch t := f.cfg.Timeout; {
+	case t <= 0:
+		return defaultRequestTimeout
+	case t < time.Millisecond:
+		return t * time.Second
+	default:
+		return t
+	}
+}
+
+// maxResponseSize 响应体大小上限 (字节)
+func (f *Fetcher) maxResponseSize() int64 {
+	if f.cfg.MaxResponseSize > 0 {
+		return f.cfg.MaxResponseSize
+	}
+	return defaultMaxResponseSize
+}
+
+// checkRedirect 限制重定向次数,并校验重定向目标
+// 原始地址为公网地址时,不允许重定向到私有地址 (防止借重定向绕过 SSRF 校验)
+func (f *Fetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	maxRedirects := f.cfg.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+	if len(via) > maxRedirects {
+		return fmt.Errorf("%w: 超过 %d 次", ErrTooManyRedirects, maxRedirects)
+	}
+	if err := f.filter.CheckURL(req.URL.String()); err != nil {
+		return fmt.Errorf("重定向目标被拒绝: %w", err)
+	}
+	if len(via) > 0 && f.isPrivateURL(via[0].URL) {
+		return nil
+	}
+	if err := f.validateURL(req.URL.String()); err != nil {
+		return fmt.Errorf("重定向目标无效: %w", err)
+	}
+	return nil
+}
+
 // validateURL 验证 URL
 func (f *Fetcher) validateURL(urlStr string) error {
 	u, err := url.Parse(urlStr)
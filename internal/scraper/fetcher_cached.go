@@ -2,112 +2,209 @@ package scraper
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/internal/urlnorm"
 	"github.com/fromsko/krio/pkg/logger"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 // CachedFetcher 带缓存和并发的抓取器
 type CachedFetcher struct {
-	fetcher    *Fetcher
-	cache      *Cache
-	semaphore  chan struct{} // 并发控制
+	fetcher   *Fetcher
+	cache     *Cache
+	ttl       time.Duration      // 缓存有效期
+	semaphore chan struct{}      // 并发控制
+	flight    singleflight.Group // 合并同一 URL 的并发抓取
 }
 
-// Cache 缓存结构
-type Cache struct {
-	mu    sync.RWMutex
-	items map[string]*cacheEntry
-}
+// FetchStatus 抓取结果相对于缓存的状态
+type FetchStatus string
 
-// cacheEntry 缓存条目
-type cacheEntry struct {
-	page      *WebPage
-	expiresAt time.Time
-}
+const (
+	StatusNew       FetchStatus = "new"       // 首次抓取
+	StatusCached    FetchStatus = "cached"    // 缓存未过期,直接使用
+	StatusUnchanged FetchStatus = "unchanged" // 缓存过期后重新验证,内容未变化
+	StatusChanged   FetchStatus = "changed"   // 缓存过期后重新抓取,内容已变化
+)
 
-// NewCache 创建缓存
-func NewCache() *Cache {
-	return &Cache{
-		items: make(map[string]*cacheEntry),
-	}
-}
+// 缓存根目录下的子目录
+const (
+	cachePagesDir     = "pages"
+	cacheSummariesDir = "summaries"
+)
 
-// Get 获取缓存
-func (c *Cache) Get(key string) (*WebPage, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// 缓存默认值 (配置为 0 时使用)
+const (
+	defaultCacheTTL             = 1 * time.Hour
+	defaultCacheMaxEntries      = 1000
+	defaultCacheMaxBytes        = 256 << 20
+	defaultCacheStaleRetention  = 7 * 24 * time.Hour
+	defaultCacheCleanupInterval = 10 * time.Minute
+)
 
-	entry, exists := c.items[key]
-	if !exists {
-		return nil, false
+// NewCachedFetcher 创建带缓存的抓取器
+// 缓存存储在缓存根目录的 pages 子目录,创建失败时退化为内存缓存
+// 同时启动后台清理任务,使用完毕后需调用 Close
+func NewCachedFetcher(cfg *config.ScraperConfig, maxConcurrency int, cacheTTL time.Duration) *CachedFetcher {
+	cacheDir := PageCacheDir(cfg)
+
+	cache, err := NewPersistentCache(cacheDir)
+	if err != nil {
+		logger.Get().Warn("创建持久化缓存失败,使用内存缓存", zap.String("dir", cacheDir), zap.Error(err))
+		cache = NewCache()
 	}
+	cache.SetLimits(
+		orDefault(cfg.CacheMaxEntries, defaultCacheMaxEntries),
+		orDefault(cfg.CacheMaxBytes, defaultCacheMaxBytes),
+	)
+	cache.StartJanitor(
+		orDefault(cfg.CacheCleanupInterval, defaultCacheCleanupInterval),
+		orDefault(cfg.CacheStaleRetention, defaultCacheStaleRetention),
+	)
 
-	// 检查是否过期
-	if time.Now().After(entry.expiresAt) {
-		return nil, false
+	return &CachedFetcher{
+		fetcher:   NewFetcher(cfg),
+		cache:     cache,
+		ttl:       orDefault(cacheTTL, defaultCacheTTL),
+		semaphore: make(chan struct{}, maxConcurrency),
 	}
+}
 
-	return entry.page, true
+// orDefault 配置值不大于 0 时返回默认值
+func orDefault[T int | int64 | time.Duration](v, def T) T {
+	if v > 0 {
+		return v
+	}
+	return def
 }
 
-// Set 设置缓存
-func (c *Cache) Set(key string, page *WebPage, ttl time.Duration) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// SummaryCacheDir 总结缓存目录: <缓存根目录>/summaries
+func SummaryCacheDir(cfg *config.ScraperConfig) string {
+	return filepath.Join(CacheRoot(cfg), cacheSummariesDir)
+}
 
-	c.items[key] = &cacheEntry{
-		page:      page,
-		expiresAt: time.Now().Add(ttl),
+// CacheRoot 缓存根目录: cfg.CacheDir,为空时使用 DefaultCacheDir
+func CacheRoot(cfg *config.ScraperConfig) string {
+	if cfg.CacheDir != "" {
+		return cfg.CacheDir
 	}
+	return DefaultCacheDir()
 }
 
-// Clear 清空缓存
-func (c *Cache) Clear() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.items = make(map[string]*cacheEntry)
+// PageCacheDir 网页缓存目录: <缓存根目录>/pages
+func PageCacheDir(cfg *config.ScraperConfig) string {
+	return filepath.Join(CacheRoot(cfg), cachePagesDir)
 }
 
-// Size 返回缓存大小
-func (c *Cache) Size() int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return len(c.items)
+// Fetch 抓取单个网页 (带缓存)
+func (f *CachedFetcher) Fetch(url string) (*WebPage, error) {
+	page, _, err := f.FetchWithStatus(url)
+	return page, err
 }
 
-// NewCachedFetcher 创建带缓存的抓取器
-func NewCachedFetcher(cfg *config.ScraperConfig, maxConcurrency int, cacheTTL time.Duration) *CachedFetcher {
-	return &CachedFetcher{
-		fetcher:   NewFetcher(cfg),
-		cache:     NewCache(),
-		semaphore: make(chan struct{}, maxConcurrency),
+// SetExtractHook 设置收到响应、开始提取正文时的回调 (命中缓存时不回调)
+func (f *CachedFetcher) SetExtractHook(fn func(url string)) {
+	f.fetcher.SetExtractHook(fn)
+}
+
+// flightResult 合并请求的共享结果
+type flightResult struct {
+	page   *WebPage
+	status FetchStatus
+}
+
+// FetchWithStatus 抓取单个网页并返回相对于缓存的状态
+// 同一 URL (按规范化结果) 的并发调用合并为一次抓取,所有调用方共享结果
+func (f *CachedFetcher) FetchWithStatus(url string) (*WebPage, FetchStatus, error) {
+	v, err, shared := f.flight.Do(urlnorm.Normalize(url), func() (interface{}, error) {
+		page, status, err := f.fetchWithStatus(url)
+		return flightResult{page: page, status: status}, err
+	})
+	if shared {
+		logger.Get().Debug("合并并发抓取请求", zap.String("url", url))
 	}
+	if err != nil {
+		return nil, "", err
+	}
+	result := v.(flightResult)
+	return result.page, result.status, nil
 }
 
-// Fetch 抓取单个网页 (带缓存)
-func (f *CachedFetcher) Fetch(url string) (*WebPage, error) {
+// fetchWithStatus 抓取单个网页 (不合并并发请求)
+// 缓存过期后携带 ETag/Last-Modified 发送条件请求,304 时直接续期缓存
+func (f *CachedFetcher) fetchWithStatus(url string) (*WebPage, FetchStatus, error) {
+	log := logger.Get()
+	key := urlnorm.Normalize(url)
+
+	// 过滤规则可能在缓存写入后修改,命中缓存时同样检查
+	if err := f.fetcher.CheckURL(url); err != nil {
+		return nil, "", err
+	}
+
 	// 尝试从缓存获取
-	if page, found := f.cache.Get(url); found {
-		logger.Get().Debug("缓存命中", zap.String("url", url))
-		return page, nil
+	entry, found := f.cache.Lookup(key)
+	if found && !entry.Expired() {
+		log.Debug("缓存命中", zap.String("url", url))
+		if err := f.fetcher.CheckContent(entry.Page); err != nil {
+			return nil, "", err
+		}
+		return entry.Page, StatusCached, nil
+	}
+
+	var validators Validators
+	if found {
+		validators = Validators{ETag: entry.Page.ETag, LastModified: entry.Page.LastModified}
+		log.Debug("缓存已过期,发送条件请求", zap.String("url", url), zap.Bool("has_validators", !validators.Empty()))
+	} else {
+		log.Debug("缓存未命中,开始抓取", zap.String("url", url))
 	}
 
-	// 缓存未命中,执行抓取
-	logger.Get().Debug("缓存未命中,开始抓取", zap.String("url", url))
-	page, err := f.fetcher.Fetch(url)
+	page, err := f.fetcher.FetchIfModified(url, validators)
+	if errors.Is(err, ErrNotModified) {
+		log.Debug("内容未变化 (304)", zap.String("url", url))
+		f.cache.Refresh(key, f.ttl)
+		if err := f.fetcher.CheckContent(entry.Page); err != nil {
+			return nil, "", err
+		}
+		return entry.Page, StatusUnchanged, nil
+	}
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	status := StatusNew
+	if found {
+		status = StatusUnchanged
+		if entry.Page.ContentHash != page.ContentHash {
+			status = StatusChanged
+		}
 	}
 
-	// 存入缓存 (默认 TTL 1小时)
-	f.cache.Set(url, page, 1*time.Hour)
-	return page, nil
+	// 存入缓存
+	f.cache.Set(key, page, f.ttl)
+	return page, status, nil
+}
+
+// NoteRecord 获取已为该 URL 生成的笔记路径及当时的内容哈希和选项摘要
+func (f *CachedFetcher) NoteRecord(url string) (notePath, contentHash, options string, ok bool) {
+	entry, found := f.cache.Entry(urlnorm.Normalize(url))
+	if !found || entry.NotePath == "" {
+		return "", "", "", false
+	}
+	return entry.NotePath, entry.NoteHash, entry.NoteOpts, true
+}
+
+// MarkNoted 记录已为该 URL 的当前内容生成笔记
+func (f *CachedFetcher) MarkNoted(url, notePath, contentHash, options string) {
+	f.cache.MarkNoted(urlnorm.Normalize(url), notePath, contentHash, options)
 }
 
 // FetchBatch 批量并发抓取网页
@@ -116,6 +213,7 @@ func (f *CachedFetcher) FetchBatch(ctx context.Context, urls []string) map[strin
 	log.Info("开始批量抓取", zap.Int("total_urls", len(urls)))
 
 	results := make(map[string]*FetchResult)
+	var mu sync.Mutex
 	var wg sync.WaitGroup
 	var successCount, failCount int32
 
@@ -131,6 +229,8 @@ func (f *CachedFetcher) FetchBatch(ctx context.Context, urls []string) map[strin
 			case f.semaphore <- struct{}{}:
 				defer func() { <-f.semaphore }()
 			case <-ctx.Done():
+				mu.Lock()
+				defer mu.Unlock()
 				results[urlStr] = &FetchResult{
 					URL:  urlStr,
 					Err:  fmt.Errorf("操作已取消"),
@@ -140,7 +240,7 @@ func (f *CachedFetcher) FetchBatch(ctx context.Context, urls []string) map[strin
 			}
 
 			// 抓取网页
-			page, err := f.Fetch(urlStr)
+			page, status, err := f.FetchWithStatus(urlStr)
 
 			// 记录结果
 			if err != nil {
@@ -151,10 +251,13 @@ func (f *CachedFetcher) FetchBatch(ctx context.Context, urls []string) map[strin
 				log.Debug("抓取成功", zap.String("url", urlStr), zap.Int("content_length", len(page.Content)))
 			}
 
+			mu.Lock()
+			defer mu.Unlock()
 			results[urlStr] = &FetchResult{
-				URL:  urlStr,
-				Err:  err,
-				Page: page,
+				URL:    urlStr,
+				Err:    err,
+				Page:   page,
+				Status: status,
 			}
 		}(url)
 	}
@@ -174,9 +277,10 @@ func (f *CachedFetcher) FetchBatch(ctx context.Context, urls []string) map[strin
 
 // FetchResult 抓取结果
 type FetchResult struct {
-	URL  string
-	Page *WebPage
-	Err  error
+	URL    string
+	Page   *WebPage
+	Status FetchStatus
+	Err    error
 }
 
 // ClearCache 清空缓存
@@ -189,3 +293,13 @@ func (f *CachedFetcher) ClearCache() {
 func (f *CachedFetcher) GetCacheSize() int {
 	return f.cache.Size()
 }
+
+// CacheStats 获取缓存统计
+func (f *CachedFetcher) CacheStats() CacheStats {
+	return f.cache.Stats()
+}
+
+// Close 停止后台清理任务并保存统计计数
+func (f *CachedFetcher) Close() {
+	f.cache.Close()
+}
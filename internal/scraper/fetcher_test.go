@@ -1,11 +1,19 @@
 package scraper
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/internal/policy"
 )
 
 func TestNewFetcher(t *testing.T) {
@@ -24,6 +32,11 @@ func TestNewFetcher(t *testing.T) {
 	if fetcher.cfg != cfg {
 		t.Error("fetcher.cfg not set correctly")
 	}
+	// 过滤规则无效时拒绝所有 URL
+	cfg.Filter.DenyPaths = []string{"re:("}
+	if err := NewFetcher(cfg).CheckURL("https://example.com/"); !errors.Is(err, policy.ErrRejected) {
+		t.Errorf("CheckURL() with invalid filter = %v, want ErrRejected", err)
+	}
 }
 
 func TestValidateURL(t *testing.T) {
@@ -158,3 +171,108 @@ func TestIsPrivateURL(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchOnce_Limits(t *testing.T) {
+	body := "<html><head><title>Big</title></head><body><p>" + strings.Repeat("x", 2048) + "</p></body></html>"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sized", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, body)
+	})
+	mux.HandleFunc("/chunked", func(w http.ResponseWriter, r *http.Request) {
+		// 分块发送,不带 Content-Length
+		w.Header().Set("Content-Type", "text/html")
+		for i := 0; i < 4; i++ {
+			fmt.Fprint(w, body)
+			w.(http.Flusher).Flush()
+		}
+	})
+	mux.HandleFunc("/redirect/", func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/redirect/"), "%d", &n)
+		http.Redirect(w, r, fmt.Sprintf("/redirect/%d", n+1), http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := NewFetcher(&config.ScraperConfig{
+		UserAgent:       "test-agent",
+		Timeout:         5 * time.Second,
+		MaxResponseSize: 1024,
+		MaxRedirects:    3,
+	})
+
+	tests := []struct {
+		name string
+		path string
+		want error
+	}{
+		{"Content-Length 预检", "/sized", ErrResponseTooLarge},
+		{"流式读取超限", "/chunked", ErrResponseTooLarge},
+		{"重定向过多", "/redirect/0", ErrTooManyRedirects},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fetcher.fetchOnce(server.URL+tt.path, Validators{})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("fetchOnce() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchWithRetry_ExtractHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		next := ""
+		if r.URL.Query().Get("page") == "" {
+			next = `<a href="?page=2">下一页</a>`
+		}
+		fmt.Fprint(w, "<html><head><title>Hook</title></head><body><article><p>"+strings.Repeat("正文内容。", 50)+"</p>"+next+"</article></body></html>")
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(&config.ScraperConfig{
+		UserAgent:  "test-agent",
+		Timeout:    5 * time.Second,
+		Pagination: config.PaginationConfig{Enabled: true},
+	})
+	// 所有主机都连接到本地测试服务器,分页地址可以通过 SSRF 校验
+	fetcher.transport.Proxy = nil
+	fetcher.transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	var extracted []string
+	fetcher.SetExtractHook(func(url string) { extracted = append(extracted, url) })
+
+	// 只对请求的地址回调,后续分页不回调
+	page, err := fetcher.fetchWithRetry("http://blog.example.com/post", Validators{})
+	if err != nil {
+		t.Fatalf("fetchWithRetry() error = %v", err)
+	}
+	if len(page.PageURLs) != 2 {
+		t.Fatalf("PageURLs = %v, want 2 pages", page.PageURLs)
+	}
+	if len(extracted) != 1 || extracted[0] != "http://blog.example.com/post" {
+		t.Errorf("extract hook calls = %v, want [http://blog.example.com/post]", extracted)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{0, defaultRequestTimeout},
+		{15, 15 * time.Second}, // 旧配置中不带单位的秒数
+		{30 * time.Second, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		f := &Fetcher{cfg: &config.ScraperConfig{Timeout: tt.timeout}}
+		if got := f.requestTimeout(); got != tt.want {
+			t.Errorf("requestTimeout(%v) = %v, want %v", tt.timeout, got, tt.want)
+		}
+	}
+}
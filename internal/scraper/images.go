@@ -0,0 +1,209 @@
+package scraper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Image 下载的图片
+type Image struct {
+	URL  string
+	Data []byte
+	Hash string // 内容 sha256 (十六进制)
+	Ext  string // 扩展名,如 .png
+}
+
+// Filename 基于内容哈希的文件名,相同图片始终得到相同文件名
+func (i *Image) Filename() string {
+	return i.Hash[:16] + i.Ext
+}
+
+// imageExts 图片媒体类型到扩展名的映射
+var imageExts = map[string]string{
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+	"image/avif":    ".avif",
+	"image/bmp":     ".bmp",
+}
+
+// imageExt 根据媒体类型 (优先) 或 URL 确定扩展名
+func imageExt(contentType, rawURL string) string {
+	if ext, ok := imageExts[parseMediaType(contentType)]; ok {
+		return ext
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		ext := strings.ToLower(path.Ext(u.Path))
+		for _, known := range imageExts {
+			if ext == known || (ext == ".jpeg" && known == ".jpg") {
+				return known
+			}
+		}
+	}
+	return ""
+}
+
+// collectImages 收集头图 (og:image) 和正文图片地址
+// 正文范围依次取 article、main、body,忽略导航/页眉/页脚/侧栏中的图片
+// base 为页面最终地址 (重定向后),用于解析相对路径
+func collectImages(page *WebPage, base *url.URL, doc *goquery.Selection) {
+	seen := make(map[string]bool)
+	resolve := func(src string) string {
+		src = strings.TrimSpace(src)
+		if src == "" || strings.HasPrefix(src, "data:") {
+			return ""
+		}
+		ref, err := base.Parse(src)
+		if err != nil || (ref.Scheme != "http" && ref.Scheme != "https") {
+			return ""
+		}
+		abs := ref.String()
+		if seen[abs] {
+			return ""
+		}
+		seen[abs] = true
+		return abs
+	}
+
+	page.LeadImage = resolve(metaContent(doc, "og:image"))
+
+	root := doc.Find("article").First()
+	if root.Length() == 0 {
+		root = doc.Find("main").First()
+	}
+	if root.Length() == 0 {
+		root = doc.Find("body")
+	}
+
+	root.Find("img").Each(func(_ int, img *goquery.Selection) {
+		if img.Closest("nav, header, footer, aside").Length() > 0 {
+			return
+		}
+		// 懒加载图片的真实地址通常在 data-src / data-original 中
+		src := img.AttrOr("data-src", img.AttrOr("data-original", img.AttrOr("src", "")))
+		if abs := resolve(src); abs != "" {
+			page.Images = append(page.Images, abs)
+		}
+	})
+}
+
+// DownloadImages 下载页面图片 (头图优先),按数量和大小限制并按内容哈希去重
+// 单张图片失败只记录日志,不影响其他图片
+func (f *Fetcher) DownloadImages(page *WebPage, cfg *config.ImageConfig) []*Image {
+	log := logger.Get()
+
+	urls := page.Images
+	if page.LeadImage != "" {
+		urls = append([]string{page.LeadImage}, urls...)
+	}
+
+	client := &http.Client{
+		Timeout:       f.requestTimeout(),
+		Transport:     f.transport,
+		Jar:           f.jar,
+		CheckRedirect: f.checkRedirect,
+	}
+	seen := make(map[string]bool)
+	var images []*Image
+
+	for _, imageURL := range urls {
+		if cfg.MaxCount > 0 && len(images) >= cfg.MaxCount {
+			break
+		}
+
+		img, err := f.downloadImage(client, imageURL, page.URL, cfg)
+		if err != nil {
+			log.Debug("跳过图片", zap.String("url", imageURL), zap.Error(err))
+			continue
+		}
+		if seen[img.Hash] {
+			continue
+		}
+		seen[img.Hash] = true
+		images = append(images, img)
+	}
+
+	log.Info("图片下载完成",
+		zap.String("url", page.URL),
+		zap.Int("candidates", len(urls)),
+		zap.Int("downloaded", len(images)),
+	)
+	return images
+}
+
+// downloadImage 下载单张图片
+func (f *Fetcher) downloadImage(client *http.Client, imageURL, referer string, cfg *config.ImageConfig) (*Image, error) {
+	if err := f.validateURL(imageURL); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.cfg.UserAgent)
+	f.applyDomainConfig(req.Header, req.URL)
+	// 部分图床有防盗链校验
+	req.Header.Set("Referer", referer)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !strings.HasPrefix(mediaType, "image/") {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+	if cfg.MaxSize > 0 && resp.ContentLength > cfg.MaxSize {
+		return nil, fmt.Errorf("图片过大: %d 字节", resp.ContentLength)
+	}
+
+	reader := io.Reader(resp.Body)
+	if cfg.MaxSize > 0 {
+		reader = io.LimitReader(resp.Body, cfg.MaxSize+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxSize > 0 && int64(len(data)) > cfg.MaxSize {
+		return nil, fmt.Errorf("图片过大: 超过 %d 字节", cfg.MaxSize)
+	}
+	if int64(len(data)) < cfg.MinSize {
+		return nil, fmt.Errorf("图片过小: %d 字节", len(data))
+	}
+
+	ext := imageExt(contentType, imageURL)
+	if ext == "" {
+		return nil, fmt.Errorf("无法识别图片格式: %s", contentType)
+	}
+
+	sum := sha256.Sum256(data)
+	return &Image{
+		URL:  imageURL,
+		Data: data,
+		Hash: hex.EncodeToString(sum[:]),
+		Ext:  ext,
+	}, nil
+}
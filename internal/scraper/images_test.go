@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestCollectImages(t *testing.T) {
+	html := `<html><head><meta property="og:image" content="/cover.png"></head><body>
+<header><img src="/logo.png"></header>
+<article>
+  <img src="diagram.png">
+  <img data-src="https://cdn.example.com/lazy.jpg" src="placeholder.gif">
+  <img src="data:image/png;base64,AAAA">
+  <img src="/cover.png">
+  <img src="diagram.png">
+</article>
+<aside><img src="/ad.png"></aside>
+</body></html>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("解析 HTML 失败: %v", err)
+	}
+
+	base, _ := url.Parse("https://example.com/posts/1")
+	page := &WebPage{URL: base.String()}
+	collectImages(page, base, doc.Selection)
+
+	if page.LeadImage != "https://example.com/cover.png" {
+		t.Errorf("LeadImage = %q", page.LeadImage)
+	}
+
+	want := []string{
+		"https://example.com/posts/diagram.png",
+		"https://cdn.example.com/lazy.jpg",
+	}
+	if strings.Join(page.Images, ",") != strings.Join(want, ",") {
+		t.Errorf("Images = %v, want %v", page.Images, want)
+	}
+}
+
+func TestImageExt(t *testing.T) {
+	tests := []struct {
+		contentType string
+		url         string
+		want        string
+	}{
+		{"image/png", "https://example.com/a", ".png"},
+		{"image/jpeg; charset=binary", "https://example.com/a.png", ".jpg"},
+		{"image/svg+xml", "https://example.com/a", ".svg"},
+		{"application/octet-stream", "https://example.com/a.JPEG", ".jpg"},
+		{"application/octet-stream", "https://example.com/a.bin", ""},
+	}
+
+	for _, tt := range tests {
+		if got := imageExt(tt.contentType, tt.url); got != tt.want {
+			t.Errorf("imageExt(%q, %q) = %q, want %q", tt.contentType, tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package scraper
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fromsko/krio/internal/urlnorm"
+	"github.com/fromsko/krio/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 默认最多拼接的页数 (含首页)
+const defaultMaxPages = 5
+
+// nextPageText 匹配"下一页"类链接文字
+var nextPageText = regexp.MustCompile(`(?i)^(下一页|下页|后一页|next|next page)\s*[>»›→]*$`)
+
+// 分页地址中的页码标记: 路径末尾的 /page/N、/N、-N、_N 或查询参数 page=N 等
+var (
+	pagePathSuffix  = regexp.MustCompile(`(?i)(?:/page/|/|-|_)(\d{1,4})$`)
+	pageExtension   = regexp.MustCompile(`(?i)\.(?:s?html?|php|aspx?|jsp)$`)
+	pageQueryParams = []string{"page", "paged", "pg", "p"}
+)
+
+// nextPageURL 查找同一主机下的下一页地址
+// 依次尝试 link/a rel=next、分页区域中 class 含 next 的链接、文字为"下一页"的链接
+// 只接受与当前页仅相差页码标记的地址,rel=next 指向下一篇文章时不跟随
+func nextPageURL(base *url.URL, doc *goquery.Selection) string {
+	candidates := []*goquery.Selection{
+		doc.Find(`link[rel~="next"], a[rel~="next"]`),
+		doc.Find(`[class*="pag"] a[class*="next"], a.next`),
+		doc.Find("a").FilterFunction(func(_ int, a *goquery.Selection) bool {
+			return nextPageText.MatchString(strings.TrimSpace(a.Text()))
+		}),
+	}
+
+	for _, sel := range candidates {
+		for i := range sel.Nodes {
+			href := strings.TrimSpace(sel.Eq(i).AttrOr("href", ""))
+			if href == "" || strings.HasPrefix(href, "#") {
+				continue
+			}
+			ref, err := base.Parse(href)
+			if err != nil || (ref.Scheme != "http" && ref.Scheme != "https") {
+				continue
+			}
+			// 只跟随同一主机,且不能指向当前页
+			if !strings.EqualFold(ref.Hostname(), base.Hostname()) || urlnorm.Equal(ref.String(), base.String()) {
+				continue
+			}
+			if !isNextPage(base, ref) {
+				continue
+			}
+			ref.Fragment = ""
+			return ref.String()
+		}
+	}
+	return ""
+}
+
+// splitPage 拆分地址为去掉页码标记后的主干和页码,没有页码标记时页码为 0
+// 主干忽略主机大小写、末尾斜杠和扩展名 (/post/1_2.html 与 /post/1 的主干相同)
+func splitPage(u *url.URL) (string, int) {
+	path := strings.TrimSuffix(pageExtension.ReplaceAllString(u.EscapedPath(), ""), "/")
+	query := u.Query()
+
+	n := 0
+	for _, name := range pageQueryParams {
+		if num, err := strconv.Atoi(query.Get(name)); err == nil && num > 0 {
+			n = num
+			query.Del(name)
+			break
+		}
+	}
+	if n == 0 {
+		if m := pagePathSuffix.FindStringSubmatchIndex(path); m != nil {
+			n, _ = strconv.Atoi(path[m[2]:m[3]])
+			path = path[:m[0]]
+		}
+	}
+	return pageStem(u.Host, path, query), n
+}
+
+// pageStem 分页地址主干
+func pageStem(host, path string, query url.Values) string {
+	return strings.ToLower(host) + path + "?" + query.Encode()
+}
+
+// isPageOf next 是否为 first 的后续分页: next 去掉页码标记后与 first 相同,且页码不小于 2
+func isPageOf(first, next *url.URL) bool {
+	stem, n := splitPage(next)
+	path := strings.TrimSuffix(pageExtension.ReplaceAllString(first.EscapedPath(), ""), "/")
+	return n >= 2 && stem == pageStem(first.Host, path, first.Query())
+}
+
+// isNextPage next 是否为当前页 cur 的下一页
+// cur 为首页时 next 需是 cur 的分页;cur 本身是第 N 页时,next 需与 cur 主干相同且页码更大
+func isNextPage(cur, next *url.URL) bool {
+	if isPageOf(cur, next) {
+		return true
+	}
+	curStem, m := splitPage(cur)
+	stem, n := splitPage(next)
+	return m >= 2 && n > m && stem == curStem
+}
+
+// maxPages 分页拼接的最大页数 (含首页)
+func (f *Fetcher) maxPages() int {
+	if f.cfg.Pagination.MaxPages > 0 {
+		return f.cfg.Pagination.MaxPages
+	}
+	return defaultMaxPages
+}
+
+// stitchPages 依次抓取后续分页,将正文拼接到首页
+// 后续页抓取失败时保留已拼接的内容,不影响首页结果
+func (f *Fetcher) stitchPages(page *WebPage) {
+	log := logger.Get()
+
+	first, err := url.Parse(page.URL)
+	if err != nil {
+		return
+	}
+
+	visited := map[string]bool{urlnorm.Normalize(page.URL): true}
+	parts := []string{page.Content}
+	pageURLs := []string{page.URL}
+
+	next := page.nextPage
+	for next != "" && len(pageURLs) < f.maxPages() {
+		key := urlnorm.Normalize(next)
+		if visited[key] {
+			break
+		}
+		visited[key] = true
+
+		// 每一页都需是首页的分页,避免沿着各页的下一页链接逐步偏离到其他文章
+		if ref, err := url.Parse(next); err != nil || !isPageOf(first, ref) {
+			log.Debug("不是首页的分页地址,停止拼接", zap.String("url", next))
+			break
+		}
+		if err := f.validateURL(next); err != nil {
+			log.Warn("分页地址无效,停止拼接", zap.String("url", next), zap.Error(err))
+			break
+		}
+		if err := f.filter.CheckURL(next); err != nil {
+			log.Debug("分页地址被过滤,停止拼接", zap.String("url", next), zap.Error(err))
+			break
+		}
+
+		part, err := f.fetchOnce(next, Validators{})
+		if err != nil {
+			log.Warn("抓取分页失败,停止拼接", zap.String("url", next), zap.Error(err))
+			break
+		}
+		if !isHTMLType(part.ContentType) {
+			break
+		}
+
+		parts = append(parts, fmt.Sprintf("--- 第 %d 页 ---\n\n%s", len(pageURLs)+1, part.Content))
+		pageURLs = append(pageURLs, next)
+		for _, image := range part.Images {
+			if !slices.Contains(page.Images, image) {
+				page.Images = append(page.Images, image)
+			}
+		}
+		next = part.nextPage
+	}
+
+	if len(pageURLs) == 1 {
+		return
+	}
+
+	page.Content = strings.Join(parts, "\n\n")
+	page.PageURLs = pageURLs
+	page.finishContent()
+
+	log.Debug("已拼接分页文章", zap.String("url", page.URL), zap.Int("pages", len(pageURLs)))
+}
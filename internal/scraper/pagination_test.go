@@ -0,0 +1,114 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fromsko/krio/internal/config"
+)
+
+func TestNextPageURL(t *testing.T) {
+	base, _ := url.Parse("https://blog.example.com/post/1")
+
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"link rel=next", `<link rel="next" href="/post/1?page=2">`, "https://blog.example.com/post/1?page=2"},
+		{"a rel=next", `<a rel="prev next" href="?page=2">»</a>`, "https://blog.example.com/post/1?page=2"},
+		{"分页 class", `<div class="pagination"><a class="next page-numbers" href="/post/1/2">»</a></div>`, "https://blog.example.com/post/1/2"},
+		{"下一页文字", `<a href="/post/1_2.html">下一页 &gt;</a>`, "https://blog.example.com/post/1_2.html"},
+		{"英文 Next", `<a href="/post/1/2">Next »</a>`, "https://blog.example.com/post/1/2"},
+		{"其他主机", `<a href="https://other.com/post/1?page=2">下一页</a>`, ""},
+		{"指向当前页", `<a href="/post/1#top">下一页</a><a href="/post/1">next</a>`, ""},
+		{"非 http 协议", `<a href="javascript:next()">下一页</a>`, ""},
+		{"无分页", `<a href="/post/2">下一篇</a>`, ""},
+		{"rel=next 指向下一篇", `<link rel="next" href="/post/2">`, ""},
+		{"跳过下一篇取分页链接", `<link rel="next" href="/post/2"><a href="/post/1/page/2/">下一页</a>`, "https://blog.example.com/post/1/page/2/"},
+		{"连字符页码", `<a href="/post/1-2">Next</a>`, "https://blog.example.com/post/1-2"},
+		{"非页码参数", `<a href="/post/1?ref=next">下一页</a>`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body>" + tt.html + "</body></html>"))
+			if err != nil {
+				t.Fatalf("解析 HTML 失败: %v", err)
+			}
+			if got := nextPageURL(base, doc.Selection); got != tt.want {
+				t.Errorf("nextPageURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetch_Pagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := 1
+		fmt.Sscanf(r.URL.Query().Get("page"), "%d", &n)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		// 每页都有下一页,验证页数上限
+		fmt.Fprintf(w, `<html><head><title>教程</title></head><body><p>第%d部分内容</p><img src="/logo.png"><img src="/%d.png"><a href="?page=%d">下一页</a></body></html>`, n, n, n+1)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		url        string
+		cfg        config.PaginationConfig
+		wantPages  int
+		wantImages int
+	}{
+		{"未启用", "http://blog.example.com/post", config.PaginationConfig{}, 0, 2},
+		{"页数上限", "http://blog.example.com/post", config.PaginationConfig{Enabled: true, MaxPages: 3}, 3, 4},
+		{"私有地址不拼接", server.URL, config.PaginationConfig{Enabled: true, MaxPages: 3}, 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := NewFetcher(&config.ScraperConfig{UserAgent: "test-agent", Timeout: 5 * time.Second, Pagination: tt.cfg})
+			// 所有主机都连接到本地测试服务器
+			fetcher.transport.Proxy = nil
+			fetcher.transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+			}
+
+			// 直接调用 fetchOnce 和 stitchPages,首页跳过 SSRF 校验
+			page, err := fetcher.fetchOnce(tt.url, Validators{})
+			if err != nil {
+				t.Fatalf("fetchOnce failed: %v", err)
+			}
+			if page.nextPage != "" {
+				fetcher.stitchPages(page)
+			}
+
+			if len(page.PageURLs) != tt.wantPages {
+				t.Fatalf("PageURLs = %v, want %d pages", page.PageURLs, tt.wantPages)
+			}
+			if len(page.Images) != tt.wantImages {
+				t.Errorf("Images = %v, want %d (重复的图片只保留一次)", page.Images, tt.wantImages)
+			}
+			if tt.wantPages == 0 {
+				return
+			}
+			assertContains(t, page.Content,
+				[]string{"第1部分内容", "--- 第 2 页 ---", "第2部分内容", "第3部分内容"},
+				[]string{"第4部分内容"},
+			)
+			before := page.ContentHash
+			page.finishContent()
+			if page.ContentHash != before {
+				t.Error("ContentHash should cover stitched content")
+			}
+		})
+	}
+}
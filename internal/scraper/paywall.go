@@ -0,0 +1,140 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fromsko/krio/internal/policy"
+)
+
+// ErrPaywalled 正文需要登录或付费才能查看 (paywall.action 为 fail 时返回)
+var ErrPaywalled = fmt.Errorf("%w: 需要登录或付费才能查看全文", policy.ErrRejected)
+
+// 付费墙处理方式
+const (
+	PaywallMark   = "mark"   // 标记为不完整,笔记中加入提示 (默认)
+	PaywallFail   = "fail"   // 视为抓取失败
+	PaywallIgnore = "ignore" // 不检测
+)
+
+// paywallMarkers 常见的登录墙/付费墙提示文字
+var paywallMarkers = []string{
+	"登录后查看全文",
+	"登录后阅读全文",
+	"登录后查看完整内容",
+	"登录即可查看全文",
+	"订阅后阅读全文",
+	"开通会员查看全文",
+	"付费后查看全文",
+	"剩余内容需付费",
+	"Subscribe to continue reading",
+	"Subscribe to read the full article",
+	"Sign in to continue reading",
+	"Sign in to read the full story",
+	"Create an account to read the full story",
+	"Member-only story",
+	"This article is for subscribers only",
+	"to continue reading, subscribe",
+}
+
+// 页面体积启发式: 正文很短但 HTML 很大,多为只返回摘要的付费文章
+const (
+	paywallMaxTeaserRunes = 600
+	paywallMinHTMLBytes   = 50 << 10
+)
+
+// detectPaywallMarkup 根据 schema.org isAccessibleForFree 和提示文字检测付费墙
+// 需在提取器修改 DOM 之前调用,返回检测原因 (未检测到时为空)
+func detectPaywallMarkup(doc *goquery.Selection, extraMarkers []string) string {
+	if !accessibleForFree(doc) {
+		return "页面声明 isAccessibleForFree=false"
+	}
+
+	text := strings.ToLower(doc.Find("body").Text())
+	for _, marker := range append(paywallMarkers, extraMarkers...) {
+		if marker != "" && strings.Contains(text, strings.ToLower(marker)) {
+			return fmt.Sprintf("页面包含提示 \"%s\"", marker)
+		}
+	}
+	return ""
+}
+
+// accessibleForFree 读取 schema.org 的 isAccessibleForFree 声明 (JSON-LD 或 microdata),未声明时视为免费
+func accessibleForFree(doc *goquery.Selection) bool {
+	free := true
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		var data any
+		if err := json.Unmarshal([]byte(s.Text()), &data); err != nil {
+			return true
+		}
+		if v, ok := findJSONKey(data, "isAccessibleForFree"); ok && isFalse(v) {
+			free = false
+			return false
+		}
+		return true
+	})
+	if !free {
+		return false
+	}
+
+	if sel := doc.Find(`[itemprop="isAccessibleForFree"]`).First(); sel.Length() > 0 {
+		value := sel.AttrOr("content", sel.Text())
+		return !isFalse(value)
+	}
+	return true
+}
+
+// findJSONKey 在 JSON 对象 (含嵌套对象和数组,如 @graph) 中查找指定键
+func findJSONKey(data any, key string) (any, bool) {
+	switch v := data.(type) {
+	case map[string]any:
+		if value, ok := v[key]; ok {
+			return value, true
+		}
+		for _, child := range v {
+			if value, ok := findJSONKey(child, key); ok {
+				return value, true
+			}
+		}
+	case []any:
+		for _, child := range v {
+			if value, ok := findJSONKey(child, key); ok {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// isFalse 判断 JSON 值或属性值是否为 false
+func isFalse(v any) bool {
+	switch b := v.(type) {
+	case bool:
+		return !b
+	case string:
+		return strings.EqualFold(strings.TrimSpace(b), "false")
+	}
+	return false
+}
+
+// detectPaywallWeight 根据正文长度和页面体积检测只返回摘要的页面
+func detectPaywallWeight(content string, htmlBytes int) string {
+	if htmlBytes < paywallMinHTMLBytes {
+		return ""
+	}
+	if n := utf8.RuneCountInString(content); n < paywallMaxTeaserRunes {
+		return fmt.Sprintf("正文仅 %d 字符,页面却有 %d KB", n, htmlBytes>>10)
+	}
+	return ""
+}
+
+// paywallAction 付费墙处理方式
+func (f *Fetcher) paywallAction() string {
+	if f.cfg.Paywall.Action == "" {
+		return PaywallMark
+	}
+	return f.cfg.Paywall.Action
+}
@@ -0,0 +1,69 @@
+package scraper
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/internal/policy"
+)
+
+func TestDetectPaywallMarkup(t *testing.T) {
+	tests := []struct {
+		name   string
+		html   string
+		extra  []string
+		reason string // 为空表示未检测到
+	}{
+		{"JSON-LD", `<script type="application/ld+json">{"@type":"NewsArticle","isAccessibleForFree":"False"}</script><p>摘要</p>`, nil, "isAccessibleForFree=false"},
+		{"JSON-LD @graph", `<script type="application/ld+json">{"@graph":[{"@type":"WebPage"},{"@type":"Article","isAccessibleForFree":false}]}</script>`, nil, "isAccessibleForFree=false"},
+		{"microdata", `<meta itemprop="isAccessibleForFree" content="false"><p>摘要</p>`, nil, "isAccessibleForFree=false"},
+		{"免费文章", `<script type="application/ld+json">{"isAccessibleForFree":true}</script><p>正文</p>`, nil, ""},
+		{"中文提示", `<p>前三段内容……</p><div class="mask">登录后查看全文</div>`, nil, "登录后查看全文"},
+		{"英文提示", `<p>Teaser</p><div>Member-only story</div>`, nil, "Member-only story"},
+		{"自定义提示", `<p>Teaser</p><div>扫码关注公众号阅读全文</div>`, []string{"扫码关注公众号阅读全文"}, "扫码关注公众号阅读全文"},
+		{"普通页面", `<p>完整的正文内容</p>`, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body>" + tt.html + "</body></html>"))
+			if err != nil {
+				t.Fatalf("解析 HTML 失败: %v", err)
+			}
+			got := detectPaywallMarkup(doc.Selection, tt.extra)
+			if tt.reason == "" && got != "" || !strings.Contains(got, tt.reason) {
+				t.Errorf("detectPaywallMarkup() = %q, want %q", got, tt.reason)
+			}
+		})
+	}
+}
+
+func TestDetectPaywallWeight(t *testing.T) {
+	if got := detectPaywallWeight("短摘要", 200<<10); got == "" {
+		t.Error("short content in heavy page should be flagged")
+	}
+	if got := detectPaywallWeight("短摘要", 10<<10); got != "" {
+		t.Errorf("light page should not be flagged, got %q", got)
+	}
+	if got := detectPaywallWeight(strings.Repeat("正文", 1000), 200<<10); got != "" {
+		t.Errorf("long content should not be flagged, got %q", got)
+	}
+}
+
+func TestCheckContent_Paywall(t *testing.T) {
+	page := &WebPage{Content: "摘要", Partial: true, PartialReason: "页面包含提示 \"登录后查看全文\""}
+
+	mark := &Fetcher{cfg: &config.ScraperConfig{}}
+	if err := mark.CheckContent(page); err != nil {
+		t.Errorf("mark mode CheckContent() = %v, want nil", err)
+	}
+
+	fail := &Fetcher{cfg: &config.ScraperConfig{Paywall: config.PaywallConfig{Action: PaywallFail}}}
+	err := fail.CheckContent(page)
+	if !errors.Is(err, ErrPaywalled) || !errors.Is(err, policy.ErrRejected) {
+		t.Errorf("fail mode CheckContent() = %v, want ErrPaywalled", err)
+	}
+}
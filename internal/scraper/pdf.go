@@ -0,0 +1,109 @@
+package scraper
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// PDF 内容类型
+const contentTypePDF = "application/pdf"
+
+// pdfDocument PDF 文本提取结果
+type pdfDocument struct {
+	Title     string
+	Content   string
+	PageCount int
+}
+
+// pdfExtractor PDF 提取器
+type pdfExtractor struct{}
+
+func (e *pdfExtractor) Name() string { return "pdf" }
+
+func (e *pdfExtractor) Match(contentType string, u *url.URL, body []byte) bool {
+	return isPDF(contentType, body)
+}
+
+func (e *pdfExtractor) Extract(page *WebPage, body []byte) error {
+	doc, err := extractPDF(body)
+	if err != nil {
+		return err
+	}
+
+	page.ContentType = contentTypePDF
+	page.PageCount = doc.PageCount
+	page.Content = doc.Content
+	if doc.Title != "" {
+		page.Title = doc.Title
+	}
+	return nil
+}
+
+// isPDF 判断响应是否为 PDF (Content-Type 或文件头)
+func isPDF(contentType string, body []byte) bool {
+	if strings.Contains(strings.ToLower(contentType), contentTypePDF) {
+		return true
+	}
+	return bytes.HasPrefix(body, []byte("%PDF-"))
+}
+
+// extractPDF 按页提取 PDF 文本,标题取自 PDF 元数据
+func extractPDF(data []byte) (doc *pdfDocument, err error) {
+	// pdf 库遇到损坏文件可能 panic,这里统一转换为错误
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("解析 PDF 失败: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("打开 PDF 失败: %w", err)
+	}
+
+	doc = &pdfDocument{
+		Title:     strings.TrimSpace(reader.Trailer().Key("Info").Key("Title").Text()),
+		PageCount: reader.NumPage(),
+	}
+
+	var sb strings.Builder
+	fonts := make(map[string]*pdf.Font)
+	for i := 1; i <= doc.PageCount; i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		// 缓存字体,避免每页重复解析字符映射
+		for _, name := range page.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				font := page.Font(name)
+				fonts[name] = &font
+			}
+		}
+
+		text, err := page.GetPlainText(fonts)
+		if err != nil {
+			return nil, fmt.Errorf("提取第 %d 页文本失败: %w", i, err)
+		}
+
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		// 保留页码标记,便于总结时引用位置
+		sb.WriteString(fmt.Sprintf("--- 第 %d 页 ---\n%s\n\n", i, text))
+	}
+
+	doc.Content = strings.TrimSpace(sb.String())
+	if doc.Content == "" {
+		return nil, fmt.Errorf("PDF 中未提取到文本 (可能是扫描件)")
+	}
+
+	return doc, nil
+}
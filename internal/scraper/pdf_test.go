@@ -0,0 +1,98 @@
+package scraper
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildTestPDF 构建一个包含元数据标题的最小多页 PDF
+func buildTestPDF(title string, pages []string) []byte {
+	var objects []string
+
+	// 1: Catalog, 2: Pages, 3: Font, 4: Info, 之后每页占两个对象 (Page + Content)
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		fmt.Sprintf("<< /Title (%s) >>", title),
+	)
+	for i, text := range pages {
+		stream := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 6+i*2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+		)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 4 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+func TestIsPDF(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		want        bool
+	}{
+		{"content type", "application/pdf", nil, true},
+		{"content type with params", "application/PDF; charset=binary", nil, true},
+		{"magic bytes", "application/octet-stream", []byte("%PDF-1.7\n"), true},
+		{"html", "text/html; charset=utf-8", []byte("<html>"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPDF(tt.contentType, tt.body); got != tt.want {
+				t.Errorf("isPDF() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractPDF(t *testing.T) {
+	data := buildTestPDF("Attention Is All You Need", []string{"Hello first page", "Second page text"})
+
+	doc, err := extractPDF(data)
+	if err != nil {
+		t.Fatalf("extractPDF failed: %v", err)
+	}
+
+	if doc.Title != "Attention Is All You Need" {
+		t.Errorf("Title = %q, want %q", doc.Title, "Attention Is All You Need")
+	}
+	if doc.PageCount != 2 {
+		t.Errorf("PageCount = %d, want 2", doc.PageCount)
+	}
+	for _, want := range []string{"--- 第 1 页 ---", "Hello first page", "--- 第 2 页 ---", "Second page text"} {
+		if !strings.Contains(doc.Content, want) {
+			t.Errorf("Content missing %q, got %q", want, doc.Content)
+		}
+	}
+}
+
+func TestExtractPDF_Invalid(t *testing.T) {
+	if _, err := extractPDF([]byte("not a pdf")); err == nil {
+		t.Error("extractPDF() expected error for invalid data")
+	}
+}
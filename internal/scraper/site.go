@@ -0,0 +1,119 @@
+package scraper
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SiteExtractor 站点专用 HTML 提取器
+type SiteExtractor interface {
+	// Name 提取器名称
+	Name() string
+	// Match 判断 URL 是否由该提取器处理
+	Match(u *url.URL) bool
+	// Extract 从 HTML 文档中提取内容并填充到 page
+	Extract(page *WebPage, doc *goquery.Selection) error
+}
+
+// SiteRegistry 站点提取器注册表
+type SiteRegistry struct {
+	extractors []SiteExtractor
+}
+
+// NewSiteRegistry 创建包含内置站点提取器的注册表
+func NewSiteRegistry() *SiteRegistry {
+	return &SiteRegistry{
+		extractors: []SiteExtractor{
+			&githubExtractor{},
+			&stackOverflowExtractor{},
+			&wikipediaExtractor{},
+			&arxivExtractor{},
+		},
+	}
+}
+
+// Register 注册站点提取器 (优先于已有提取器匹配)
+func (r *SiteRegistry) Register(e SiteExtractor) {
+	r.extractors = append([]SiteExtractor{e}, r.extractors...)
+}
+
+// Lookup 查找匹配的站点提取器,未匹配返回 nil
+func (r *SiteRegistry) Lookup(u *url.URL) SiteExtractor {
+	if u == nil {
+		return nil
+	}
+	for _, e := range r.extractors {
+		if e.Match(u) {
+			return e
+		}
+	}
+	return nil
+}
+
+// extractGenericHTML 通用 HTML 提取: 标题取 title (缺失时取 h1),正文取 body 文本
+func extractGenericHTML(page *WebPage, doc *goquery.Selection) {
+	page.Title = strings.TrimSpace(doc.Find("title").First().Text())
+	if page.Title == "" {
+		page.Title = strings.TrimSpace(doc.Find("h1").First().Text())
+	}
+
+	body := doc.Find("body")
+	// 移除不需要的元素
+	body.Find("script, style, nav, header, footer, iframe, noscript").Remove()
+
+	// 提取文本内容
+	page.Content = strings.TrimSpace(body.Text())
+}
+
+// hostMatches 判断主机名是否为 domain 或其子域名
+func hostMatches(u *url.URL, domain string) bool {
+	host := strings.ToLower(u.Hostname())
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// metaContent 获取 meta 标签内容 (name 或 property)
+func metaContent(doc *goquery.Selection, key string) string {
+	sel := doc.Find(fmt.Sprintf(`meta[name="%s"], meta[property="%s"]`, key, key)).First()
+	return strings.TrimSpace(sel.AttrOr("content", ""))
+}
+
+// canonicalURL 获取页面声明的规范地址 (link rel=canonical),解析为绝对地址
+// 只接受 http/https 地址,缺失或无效时返回空
+func canonicalURL(base *url.URL, doc *goquery.Selection) string {
+	href := strings.TrimSpace(doc.Find(`link[rel="canonical"]`).First().AttrOr("href", ""))
+	if href == "" {
+		return ""
+	}
+	ref, err := base.Parse(href)
+	if err != nil || (ref.Scheme != "http" && ref.Scheme != "https") || ref.Host == "" {
+		return ""
+	}
+	return ref.String()
+}
+
+// blankLines 匹配连续空行
+var blankLines = regexp.MustCompile(`\n[ \t]*(\n[ \t]*)+`)
+
+// textWithCode 提取文本并将 pre 代码块转换为 Markdown 代码块
+// 会修改传入的选择集,调用方需传入可丢弃的节点
+func textWithCode(sel *goquery.Selection) string {
+	sel.Find("pre").Each(func(_ int, pre *goquery.Selection) {
+		lang := ""
+		for _, class := range strings.Fields(pre.Find("code").AttrOr("class", pre.AttrOr("class", ""))) {
+			if strings.HasPrefix(class, "lang-") || strings.HasPrefix(class, "language-") {
+				lang = class[strings.Index(class, "-")+1:]
+				break
+			}
+		}
+		block := "\n\n" + fencedBlock(lang, strings.TrimRight(pre.Text(), "\n")) + "\n\n"
+		pre.ReplaceWithHtml("<div>" + html.EscapeString(block) + "</div>")
+	})
+
+	text := strings.TrimSpace(sel.Text())
+	return blankLines.ReplaceAllString(text, "\n\n")
+}
@@ -0,0 +1,78 @@
+package scraper
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// arxivExtractor arXiv 摘要页提取器: 标题、作者、摘要和 PDF 链接
+type arxivExtractor struct{}
+
+func (e *arxivExtractor) Name() string { return "arxiv" }
+
+func (e *arxivExtractor) Match(u *url.URL) bool {
+	return hostMatches(u, "arxiv.org") && strings.HasPrefix(u.Path, "/abs/")
+}
+
+func (e *arxivExtractor) Extract(page *WebPage, doc *goquery.Selection) error {
+	// 优先使用 citation 元数据,缺失时回退到页面元素
+	title := metaContent(doc, "citation_title")
+	if title == "" {
+		heading := doc.Find("h1.title").First()
+		heading.Find(".descriptor").Remove()
+		title = strings.TrimSpace(heading.Text())
+	}
+
+	var authors []string
+	doc.Find(`meta[name="citation_author"]`).Each(func(_ int, m *goquery.Selection) {
+		authors = append(authors, strings.TrimSpace(m.AttrOr("content", "")))
+	})
+	if len(authors) == 0 {
+		doc.Find(".authors a").Each(func(_ int, a *goquery.Selection) {
+			authors = append(authors, strings.TrimSpace(a.Text()))
+		})
+	}
+
+	abstractSel := doc.Find("blockquote.abstract").First()
+	abstractSel.Find(".descriptor").Remove()
+	abstract := strings.Join(strings.Fields(abstractSel.Text()), " ")
+	if abstract == "" {
+		abstract = metaContent(doc, "citation_abstract")
+	}
+	if abstract == "" {
+		return fmt.Errorf("未找到论文摘要")
+	}
+
+	pdfURL := metaContent(doc, "citation_pdf_url")
+	if pdfURL == "" {
+		if href, ok := doc.Find("a.download-pdf").First().Attr("href"); ok {
+			if base, err := url.Parse(page.URL); err == nil {
+				if ref, err := base.Parse(href); err == nil {
+					pdfURL = ref.String()
+				}
+			}
+		}
+	}
+
+	page.Site = e.Name()
+	page.Title = title
+	page.setMetadata("authors", strings.Join(authors, ", "))
+	page.setMetadata("pdf_url", pdfURL)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("论文: %s\n", title))
+	if len(authors) > 0 {
+		sb.WriteString(fmt.Sprintf("作者: %s\n", strings.Join(authors, ", ")))
+	}
+	if pdfURL != "" {
+		sb.WriteString(fmt.Sprintf("PDF: %s\n", pdfURL))
+	}
+	sb.WriteString("\n摘要:\n")
+	sb.WriteString(abstract)
+
+	page.Content = sb.String()
+	return nil
+}
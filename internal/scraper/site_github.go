@@ -0,0 +1,80 @@
+package scraper
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// githubExtractor GitHub 仓库首页提取器: README + 描述/Star/主要语言
+type githubExtractor struct{}
+
+func (e *githubExtractor) Name() string { return "github" }
+
+// Match 仅匹配仓库首页 (github.com/owner/repo)
+func (e *githubExtractor) Match(u *url.URL) bool {
+	if strings.ToLower(u.Hostname()) != "github.com" {
+		return false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	return len(parts) == 2 && parts[0] != "" && parts[1] != ""
+}
+
+func (e *githubExtractor) Extract(page *WebPage, doc *goquery.Selection) error {
+	u, err := url.Parse(page.URL)
+	if err != nil {
+		return fmt.Errorf("解析 URL 失败: %w", err)
+	}
+	repo := strings.Trim(u.Path, "/")
+
+	readme := doc.Find("article.markdown-body").First()
+	if readme.Length() == 0 {
+		return fmt.Errorf("未找到 README")
+	}
+
+	description := strings.TrimSpace(doc.Find(".BorderGrid-cell p.f4").First().Text())
+	if description == "" {
+		description = metaContent(doc, "og:description")
+	}
+
+	stars := strings.TrimSpace(doc.Find("#repo-stars-counter-star").First().AttrOr("title", ""))
+	if stars == "" {
+		stars = strings.TrimSpace(doc.Find("#repo-stars-counter-star").First().Text())
+	}
+
+	// 语言列表中的第一项为主要语言
+	var language string
+	doc.Find("h2").EachWithBreak(func(_ int, h *goquery.Selection) bool {
+		if strings.TrimSpace(h.Text()) != "Languages" {
+			return true
+		}
+		language = strings.TrimSpace(h.NextAllFiltered("ul").First().Find("span.text-bold").First().Text())
+		return false
+	})
+
+	page.Site = e.Name()
+	page.Title = repo
+	page.setMetadata("repo", repo)
+	page.setMetadata("description", description)
+	page.setMetadata("stars", stars)
+	page.setMetadata("language", language)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("仓库: %s\n", repo))
+	if description != "" {
+		sb.WriteString(fmt.Sprintf("描述: %s\n", description))
+	}
+	if stars != "" {
+		sb.WriteString(fmt.Sprintf("Stars: %s\n", stars))
+	}
+	if language != "" {
+		sb.WriteString(fmt.Sprintf("主要语言: %s\n", language))
+	}
+	sb.WriteString("\nREADME:\n\n")
+	sb.WriteString(textWithCode(readme))
+
+	page.Content = sb.String()
+	return nil
+}
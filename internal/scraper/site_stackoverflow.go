@@ -0,0 +1,92 @@
+package scraper
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// 保留的最高票回答数 (不含已采纳回答)
+const stackOverflowTopAnswers = 3
+
+// stackOverflowExtractor Stack Overflow / Stack Exchange 问答提取器
+// 保留问题、已采纳回答和最高票回答 (含代码)
+type stackOverflowExtractor struct{}
+
+func (e *stackOverflowExtractor) Name() string { return "stackoverflow" }
+
+func (e *stackOverflowExtractor) Match(u *url.URL) bool {
+	if !hostMatches(u, "stackoverflow.com") && !hostMatches(u, "stackexchange.com") &&
+		!hostMatches(u, "superuser.com") && !hostMatches(u, "serverfault.com") {
+		return false
+	}
+	return strings.HasPrefix(u.Path, "/questions/")
+}
+
+// soAnswer 回答
+type soAnswer struct {
+	score    int
+	accepted bool
+	body     *goquery.Selection
+}
+
+func (e *stackOverflowExtractor) Extract(page *WebPage, doc *goquery.Selection) error {
+	question := doc.Find(".question .js-post-body").First()
+	if question.Length() == 0 {
+		return fmt.Errorf("未找到问题内容")
+	}
+
+	title := strings.TrimSpace(doc.Find("#question-header h1").First().Text())
+	if title == "" {
+		title = metaContent(doc, "og:title")
+	}
+
+	var answers []soAnswer
+	doc.Find(".answer").Each(func(_ int, a *goquery.Selection) {
+		score, _ := strconv.Atoi(a.AttrOr("data-score", "0"))
+		answers = append(answers, soAnswer{
+			score:    score,
+			accepted: a.HasClass("accepted-answer"),
+			body:     a.Find(".js-post-body").First(),
+		})
+	})
+
+	// 已采纳回答优先,其余按票数降序
+	sort.SliceStable(answers, func(i, j int) bool {
+		if answers[i].accepted != answers[j].accepted {
+			return answers[i].accepted
+		}
+		return answers[i].score > answers[j].score
+	})
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("问题: %s\n\n", title))
+	sb.WriteString(textWithCode(question))
+	sb.WriteString("\n\n")
+
+	kept := 0
+	for _, a := range answers {
+		if !a.accepted && kept >= stackOverflowTopAnswers {
+			break
+		}
+		label := "回答"
+		if a.accepted {
+			label = "已采纳回答"
+		} else {
+			kept++
+		}
+		sb.WriteString(fmt.Sprintf("## %s (得分 %d)\n\n", label, a.score))
+		sb.WriteString(textWithCode(a.body))
+		sb.WriteString("\n\n")
+	}
+
+	page.Site = e.Name()
+	page.Title = title
+	page.Content = strings.TrimSpace(sb.String())
+	page.setMetadata("answers", strconv.Itoa(len(answers)))
+	return nil
+}
@@ -0,0 +1,181 @@
+package scraper
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// loadSiteFixture 加载保存的站点 HTML 样本
+func loadSiteFixture(t *testing.T, name string) *goquery.Selection {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join("testdata", "sites", name))
+	if err != nil {
+		t.Fatalf("打开样本失败: %v", err)
+	}
+	defer f.Close()
+
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatalf("解析样本失败: %v", err)
+	}
+	return doc.Selection
+}
+
+func TestSiteRegistry_Lookup(t *testing.T) {
+	registry := NewSiteRegistry()
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/fromsko/krio", "github"},
+		{"https://github.com/fromsko/krio/issues", ""},
+		{"https://github.com/fromsko", ""},
+		{"https://stackoverflow.com/questions/19239449/how-do-i-reverse-a-slice", "stackoverflow"},
+		{"https://unix.stackexchange.com/questions/1/x", "stackoverflow"},
+		{"https://stackoverflow.com/tags", ""},
+		{"https://en.wikipedia.org/wiki/Go_(programming_language)", "wikipedia"},
+		{"https://zh.wikipedia.org/wiki/Go", "wikipedia"},
+		{"https://arxiv.org/abs/1706.03762", "arxiv"},
+		{"https://arxiv.org/pdf/1706.03762", ""},
+		{"https://example.com/article", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, _ := url.Parse(tt.url)
+			got := ""
+			if e := registry.Lookup(u); e != nil {
+				got = e.Name()
+			}
+			if got != tt.want {
+				t.Errorf("Lookup(%s) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+// assertContains 检查内容包含/不包含指定片段
+func assertContains(t *testing.T, content string, want, notWant []string) {
+	t.Helper()
+	for _, s := range want {
+		if !strings.Contains(content, s) {
+			t.Errorf("content missing %q\n%s", s, content)
+		}
+	}
+	for _, s := range notWant {
+		if strings.Contains(content, s) {
+			t.Errorf("content should not contain %q\n%s", s, content)
+		}
+	}
+}
+
+func TestGithubExtractor(t *testing.T) {
+	page := &WebPage{URL: "https://github.com/fromsko/krio"}
+	if err := (&githubExtractor{}).Extract(page, loadSiteFixture(t, "github.html")); err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if page.Title != "fromsko/krio" {
+		t.Errorf("Title = %q, want fromsko/krio", page.Title)
+	}
+	if page.Metadata["stars"] != "1,234" || page.Metadata["language"] != "Go" {
+		t.Errorf("unexpected metadata: %v", page.Metadata)
+	}
+	assertContains(t, page.Content,
+		[]string{"描述: 智能网页笔记 Agent", "Stars: 1,234", "主要语言: Go", "README:", "```\ngo install github.com/fromsko/krio@latest\n```"},
+		[]string{"Sign in", "© GitHub"},
+	)
+}
+
+func TestStackOverflowExtractor(t *testing.T) {
+	page := &WebPage{URL: "https://stackoverflow.com/questions/19239449/how-do-i-reverse-a-slice"}
+	if err := (&stackOverflowExtractor{}).Extract(page, loadSiteFixture(t, "stackoverflow.html")); err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if page.Title != "How do I reverse a slice?" {
+		t.Errorf("Title = %q", page.Title)
+	}
+	assertContains(t, page.Content,
+		[]string{"```go\ns := []int{1, 2, 3}\n```", "## 已采纳回答 (得分 80)", "slices.Reverse(s)", "## 回答 (得分 120)", "for i, j := 0"},
+		[]string{"Low score answer", "Hot Network Questions", "Home Questions"},
+	)
+
+	// 已采纳回答排在最前
+	if strings.Index(page.Content, "已采纳回答") > strings.Index(page.Content, "得分 120") {
+		t.Error("accepted answer should come first")
+	}
+}
+
+func TestWikipediaExtractor(t *testing.T) {
+	page := &WebPage{URL: "https://en.wikipedia.org/wiki/Go_(programming_language)"}
+	if err := (&wikipediaExtractor{}).Extract(page, loadSiteFixture(t, "wikipedia.html")); err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if page.Title != "Go (programming language)" {
+		t.Errorf("Title = %q", page.Title)
+	}
+	assertContains(t, page.Content,
+		[]string{"statically typed", "History", "designed at Google in 2007", "Reception", "widely used for cloud services"},
+		[]string{"[1]", "[edit]", "Reference text", "Text after references", "navbox", "redirects here", "Categories",
+			"See also", "List of programming languages", "Related languages", "Limbo", "Influences", "Oberon"},
+	)
+}
+
+func TestArxivExtractor(t *testing.T) {
+	page := &WebPage{URL: "https://arxiv.org/abs/1706.03762"}
+	if err := (&arxivExtractor{}).Extract(page, loadSiteFixture(t, "arxiv.html")); err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if page.Title != "Attention Is All You Need" {
+		t.Errorf("Title = %q", page.Title)
+	}
+	if page.Metadata["pdf_url"] != "https://arxiv.org/pdf/1706.03762" {
+		t.Errorf("pdf_url = %q", page.Metadata["pdf_url"])
+	}
+	assertContains(t, page.Content,
+		[]string{"作者: Vaswani, Ashish, Shazeer, Noam", "PDF: https://arxiv.org/pdf/1706.03762", "the Transformer."},
+		[]string{"Abstract:", "arXiv > cs"},
+	)
+}
+
+func TestCanonicalURL(t *testing.T) {
+	base, _ := url.Parse("https://example.com/post/1?utm_source=feed")
+
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"绝对地址", `<link rel="canonical" href="https://example.com/post/1">`, "https://example.com/post/1"},
+		{"相对地址", `<link rel="canonical" href="/p/1">`, "https://example.com/p/1"},
+		{"非 http 协议", `<link rel="canonical" href="javascript:void(0)">`, ""},
+		{"缺失", `<title>无</title>`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><head>" + tt.html + "</head></html>"))
+			if err != nil {
+				t.Fatalf("解析 HTML 失败: %v", err)
+			}
+			if got := canonicalURL(base, doc.Selection); got != tt.want {
+				t.Errorf("canonicalURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	page := &WebPage{URL: "https://Example.com/post/1/?utm_source=feed", Canonical: ""}
+	if got := page.SourceURL(); got != "https://example.com/post/1" {
+		t.Errorf("SourceURL() = %q, want %q", got, "https://example.com/post/1")
+	}
+}
@@ -0,0 +1,54 @@
+package scraper
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// wikipediaNoise Wikipedia 正文中需要移除的元素 (导航框、参考文献、编辑链接等)
+const wikipediaNoise = ".navbox, .vertical-navbox, .sidebar, .reflist, .references, .refbegin, " +
+	"sup.reference, .mw-editsection, .mw-references-wrap, .toc, #toc, .hatnote, .metadata, " +
+	".ambox, .sistersitebox, .noprint, .mw-empty-elt, style, script"
+
+// wikipediaExtractor Wikipedia 条目提取器
+type wikipediaExtractor struct{}
+
+func (e *wikipediaExtractor) Name() string { return "wikipedia" }
+
+func (e *wikipediaExtractor) Match(u *url.URL) bool {
+	return hostMatches(u, "wikipedia.org") && strings.HasPrefix(u.Path, "/wiki/")
+}
+
+func (e *wikipediaExtractor) Extract(page *WebPage, doc *goquery.Selection) error {
+	body := doc.Find("#mw-content-text .mw-parser-output").First()
+	if body.Length() == 0 {
+		return fmt.Errorf("未找到条目正文")
+	}
+
+	body.Find(wikipediaNoise).Remove()
+
+	// 移除参考文献/外部链接等章节标题及其后续内容
+	body.Find("h2").Each(func(_ int, h *goquery.Selection) {
+		id := h.AttrOr("id", h.Find(".mw-headline").AttrOr("id", ""))
+		switch id {
+		case "References", "Notes", "External_links", "Further_reading", "See_also",
+			"参考文献", "参考资料", "外部链接", "延伸阅读", "参见":
+			heading := h
+			// 新版页面标题包裹在 div.mw-heading 中
+			if h.Parent().HasClass("mw-heading") {
+				heading = h.Parent()
+			}
+			// 只在下一个二级标题处停止,章节内的 h3/h4 小节 (div.mw-heading3 等) 一并移除
+			heading.NextUntil("h2, div.mw-heading2").Remove()
+			heading.Remove()
+		}
+	})
+
+	page.Site = e.Name()
+	page.Title = strings.TrimSpace(doc.Find("#firstHeading").First().Text())
+	page.Content = textWithCode(body)
+	return nil
+}
@@ -0,0 +1,189 @@
+package scraper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/pkg/logger"
+	"go.uber.org/zap"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// newTransport 创建带代理设置的 HTTP Transport
+// 未配置代理地址时沿用 HTTP_PROXY/HTTPS_PROXY/NO_PROXY 环境变量
+func newTransport(cfg *config.ProxyConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.URL == "" && len(cfg.NoProxy) == 0 {
+		return transport, nil
+	}
+
+	env := httpproxy.FromEnvironment()
+	proxyCfg := &httpproxy.Config{
+		HTTPProxy:  env.HTTPProxy,
+		HTTPSProxy: env.HTTPSProxy,
+		NoProxy:    env.NoProxy,
+	}
+	if cfg.URL != "" {
+		u, err := url.Parse(cfg.URL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("代理地址无效")
+		}
+		proxyCfg.HTTPProxy = cfg.URL
+		proxyCfg.HTTPSProxy = cfg.URL
+	}
+	if len(cfg.NoProxy) > 0 {
+		proxyCfg.NoProxy = strings.Join(cfg.NoProxy, ",")
+	}
+
+	proxyFunc := proxyCfg.ProxyFunc()
+	transport.Proxy = func(r *http.Request) (*url.URL, error) {
+		return proxyFunc(r.URL)
+	}
+	return transport, nil
+}
+
+// redactURL 隐藏 URL 中的用户名和密码,用于日志
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "<invalid>"
+	}
+	return u.Redacted()
+}
+
+// newCookieJar 创建 Cookie Jar,预先加载 cookies.txt 和按域名配置的 Cookie
+func newCookieJar(cfg *config.ScraperConfig) (http.CookieJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.CookiesFile != "" {
+		path := cfg.CookiesFile
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, rest)
+			}
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("打开 cookies 文件失败: %w", err)
+		}
+		defer file.Close()
+
+		count, err := loadNetscapeCookies(jar, file)
+		if err != nil {
+			return nil, fmt.Errorf("解析 cookies 文件失败: %w", err)
+		}
+		// 只记录数量,不记录 Cookie 内容
+		logger.Get().Debug("已加载 cookies 文件", zap.String("file", cfg.CookiesFile), zap.Int("cookies", count))
+	}
+
+	for domain, dc := range cfg.Domains {
+		if len(dc.Cookies) == 0 {
+			continue
+		}
+		domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+		cookies := make([]*http.Cookie, 0, len(dc.Cookies))
+		for name, value := range dc.Cookies {
+			cookies = append(cookies, &http.Cookie{Name: name, Value: value, Path: "/", Domain: domain})
+		}
+		// 同时设置 http 和 https,Domain 属性使其对子域名生效
+		for _, scheme := range []string{"https", "http"} {
+			jar.SetCookies(&url.URL{Scheme: scheme, Host: domain, Path: "/"}, cookies)
+		}
+	}
+
+	return jar, nil
+}
+
+// loadNetscapeCookies 解析 Netscape 格式的 cookies.txt 并写入 jar
+// 每行格式: domain \t include_subdomains \t path \t secure \t expires \t name \t value
+// 以 #HttpOnly_ 开头的行是 HttpOnly Cookie,其余 # 开头的行是注释
+func loadNetscapeCookies(jar http.CookieJar, r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	count := 0
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := false
+		if strings.HasPrefix(line, "#HttpOnly_") {
+			line = strings.TrimPrefix(line, "#HttpOnly_")
+			httpOnly = true
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return count, fmt.Errorf("第 %d 行格式错误: 应为 7 个字段", lineNo)
+		}
+
+		domain := strings.ToLower(fields[0])
+		host := strings.TrimPrefix(domain, ".")
+		secure := strings.EqualFold(fields[3], "TRUE")
+		cookie := &http.Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Path:     fields[2],
+			Secure:   secure,
+			HttpOnly: httpOnly,
+		}
+		if strings.EqualFold(fields[1], "TRUE") {
+			cookie.Domain = host
+		}
+		if expires, err := strconv.ParseInt(fields[4], 10, 64); err == nil && expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+
+		scheme := "http"
+		if secure {
+			scheme = "https"
+		}
+		jar.SetCookies(&url.URL{Scheme: scheme, Host: host, Path: cookie.Path}, []*http.Cookie{cookie})
+		count++
+	}
+	return count, scanner.Err()
+}
+
+// applyDomainConfig 按目标域名设置 User-Agent 和额外请求头
+// 多个配置同时匹配时 (如 example.com 和 docs.example.com),更具体的域名优先
+func (f *Fetcher) applyDomainConfig(header http.Header, u *url.URL) {
+	for _, domain := range matchingDomains(f.cfg.Domains, u) {
+		dc := f.cfg.Domains[domain]
+		if dc.UserAgent != "" {
+			header.Set("User-Agent", dc.UserAgent)
+		}
+		for name, value := range dc.Headers {
+			header.Set(name, value)
+		}
+	}
+}
+
+// matchingDomains 返回与 URL 匹配的域名配置键,按长度升序 (越具体越靠后)
+func matchingDomains(domains map[string]config.DomainConfig, u *url.URL) []string {
+	var matched []string
+	for domain := range domains {
+		if hostMatches(u, strings.TrimPrefix(strings.ToLower(domain), ".")) {
+			matched = append(matched, domain)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return len(matched[i]) < len(matched[j])
+	})
+	return matched
+}
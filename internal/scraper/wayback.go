@@ -0,0 +1,146 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fromsko/krio/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ErrDeadLink 链接已失效 (404/410 或域名无法解析)
+var ErrDeadLink = errors.New("链接已失效")
+
+// 默认的存档服务地址 (Wayback Machine)
+const defaultArchiveBaseURL = "https://archive.org"
+
+// waybackTimestamp Wayback 快照时间戳格式
+const waybackTimestamp = "20060102150405"
+
+// isDeadLink 判断请求失败是否意味着链接已失效
+func isDeadLink(statusCode int, err error) bool {
+	if statusCode == http.StatusNotFound || statusCode == http.StatusGone {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// waybackAvailability Wayback availability API 响应
+type waybackAvailability struct {
+	ArchivedSnapshots struct {
+		Closest *struct {
+			Available bool   `json:"available"`
+			URL       string `json:"url"`
+			Timestamp string `json:"timestamp"`
+			Status    string `json:"status"`
+		} `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+// archiveBaseURL 存档服务地址
+func (f *Fetcher) archiveBaseURL() string {
+	if f.cfg.ArchiveFallback.BaseURL != "" {
+		return strings.TrimSuffix(f.cfg.ArchiveFallback.BaseURL, "/")
+	}
+	return defaultArchiveBaseURL
+}
+
+// findSnapshot 查询最接近的存档快照,返回快照地址 (原始内容模式) 和快照时间
+func (f *Fetcher) findSnapshot(urlStr string) (string, time.Time, error) {
+	base := f.archiveBaseURL()
+	api := base + "/wayback/available?url=" + url.QueryEscape(urlStr)
+
+	client := &http.Client{Timeout: f.requestTimeout(), Transport: f.transport}
+	resp, err := client.Get(api)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("查询存档失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("查询存档失败: HTTP %d", resp.StatusCode)
+	}
+
+	var result waybackAvailability
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", time.Time{}, fmt.Errorf("解析存档响应失败: %w", err)
+	}
+
+	closest := result.ArchivedSnapshots.Closest
+	if closest == nil || !closest.Available || closest.URL == "" {
+		return "", time.Time{}, fmt.Errorf("没有可用的存档快照")
+	}
+	if closest.Status != "" && closest.Status != "200" {
+		return "", time.Time{}, fmt.Errorf("存档快照状态异常: %s", closest.Status)
+	}
+
+	snapshotAt, err := time.Parse(waybackTimestamp, closest.Timestamp)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("存档时间戳无效: %s", closest.Timestamp)
+	}
+
+	snapshotURL := rawSnapshotURL(closest.URL, closest.Timestamp)
+
+	// 快照地址来自外部响应,不在存档服务主机上时需要做 SSRF 校验
+	if !sameHost(snapshotURL, base) {
+		if err := f.validateURL(snapshotURL); err != nil {
+			return "", time.Time{}, fmt.Errorf("存档快照地址无效: %w", err)
+		}
+	}
+
+	return snapshotURL, snapshotAt, nil
+}
+
+// rawSnapshotURL 将快照地址转换为原始内容模式 (时间戳后加 id_),不注入 Wayback 工具栏和链接改写
+func rawSnapshotURL(snapshotURL, timestamp string) string {
+	marker := "/web/" + timestamp + "/"
+	return strings.Replace(snapshotURL, marker, "/web/"+timestamp+"id_/", 1)
+}
+
+// sameHost 判断两个地址的主机名是否相同
+func sameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Hostname(), ub.Hostname())
+}
+
+// fetchArchived 抓取失效链接的存档快照
+// 返回的页面 URL 仍为原地址,ArchivedFrom 记录快照地址
+func (f *Fetcher) fetchArchived(urlStr string) (*WebPage, error) {
+	snapshotURL, snapshotAt, err := f.findSnapshot(urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Get().Info("链接已失效,使用存档快照",
+		zap.String("url", urlStr),
+		zap.String("snapshot", snapshotURL),
+		zap.Time("snapshot_at", snapshotAt),
+	)
+
+	page, err := f.fetchOnce(snapshotURL, Validators{})
+	if err != nil {
+		return nil, fmt.Errorf("抓取存档快照失败: %w", err)
+	}
+
+	page.URL = urlStr
+	page.ArchivedFrom = snapshotURL
+	page.ArchivedAt = snapshotAt
+	// 快照的缓存验证器不适用于原地址
+	page.ETag = ""
+	page.LastModified = ""
+	return page, nil
+}
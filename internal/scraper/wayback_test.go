@@ -0,0 +1,98 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fromsko/krio/internal/config"
+)
+
+func TestIsDeadLink(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		err    error
+		want   bool
+	}{
+		{"404", http.StatusNotFound, errors.New("Not Found"), true},
+		{"410", http.StatusGone, errors.New("Gone"), true},
+		{"500", http.StatusInternalServerError, errors.New("Internal Server Error"), false},
+		{"域名不存在", 0, fmt.Errorf("dial: %w", &net.DNSError{Err: "no such host", Name: "x.invalid", IsNotFound: true}), true},
+		{"DNS 超时", 0, &net.DNSError{Err: "timeout", Name: "x.com", IsTimeout: true}, false},
+		{"连接被拒绝", 0, errors.New("connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDeadLink(tt.status, tt.err); got != tt.want {
+				t.Errorf("isDeadLink() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchArchived(t *testing.T) {
+	const timestamp = "20200102030405"
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/gone":
+			w.WriteHeader(http.StatusGone)
+		case r.URL.Path == "/wayback/available":
+			original := r.URL.Query().Get("url")
+			resp := map[string]any{"archived_snapshots": map[string]any{}}
+			if strings.HasSuffix(original, "/gone") {
+				resp["archived_snapshots"] = map[string]any{"closest": map[string]any{
+					"available": true,
+					"status":    "200",
+					"timestamp": timestamp,
+					"url":       server.URL + "/web/" + timestamp + "/" + original,
+				}}
+			}
+			json.NewEncoder(w).Encode(resp)
+		case strings.HasPrefix(r.URL.Path, "/web/"+timestamp+"id_/"):
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, "<html><head><title>旧文章</title></head><body><p>存档内容</p></body></html>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(&config.ScraperConfig{
+		UserAgent:       "test-agent",
+		Timeout:         5 * time.Second,
+		ArchiveFallback: config.ArchiveFallbackConfig{Enabled: true, BaseURL: server.URL},
+	})
+
+	// fetchOnce 不做 SSRF 校验,可以直接访问本地测试服务器
+	original := server.URL + "/gone"
+	if _, err := fetcher.fetchOnce(original, Validators{}); !errors.Is(err, ErrDeadLink) {
+		t.Fatalf("fetchOnce() error = %v, want ErrDeadLink", err)
+	}
+
+	page, err := fetcher.fetchArchived(original)
+	if err != nil {
+		t.Fatalf("fetchArchived failed: %v", err)
+	}
+	if page.URL != original || page.Title != "旧文章" || !strings.Contains(page.Content, "存档内容") {
+		t.Errorf("unexpected page: %+v", page)
+	}
+	if want := server.URL + "/web/" + timestamp + "id_/" + original; page.ArchivedFrom != want {
+		t.Errorf("ArchivedFrom = %q, want %q", page.ArchivedFrom, want)
+	}
+	if !page.ArchivedAt.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("ArchivedAt = %v", page.ArchivedAt)
+	}
+
+	if _, err := fetcher.fetchArchived(server.URL + "/never-archived"); err == nil {
+		t.Error("fetchArchived() should fail without snapshot")
+	}
+}
@@ -0,0 +1,130 @@
+package summarizer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fromsko/krio/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Cache 总结缓存
+// 与网页缓存使用同一缓存根目录,每个条目保存为一个 JSON 文件
+type Cache struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// cacheEntry 总结缓存条目
+type cacheEntry struct {
+	Key           string    `json:"key"`
+	Model         string    `json:"model"`
+	PromptVersion string    `json:"prompt_version"`
+	Summary       *Summary  `json:"summary"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// NewCache 创建总结缓存
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建总结缓存目录失败: %w", err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Get 获取缓存的总结
+func (c *Cache) Get(key string) (*Summary, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := os.ReadFile(c.entryFile(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key || entry.Summary == nil {
+		return nil, false
+	}
+	return entry.Summary, true
+}
+
+// Set 写入总结缓存 (不保存原文)
+func (c *Cache) Set(key, model, promptVersion string, summary *Summary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored := *summary
+	stored.OriginalContent = ""
+
+	data, err := json.Marshal(cacheEntry{
+		Key:           key,
+		Model:         model,
+		PromptVersion: promptVersion,
+		Summary:       &stored,
+		CreatedAt:     time.Now(),
+	})
+	if err != nil {
+		logger.Get().Warn("序列化总结缓存失败", zap.Error(err))
+		return
+	}
+
+	// 先写临时文件再重命名,避免中断时留下半个文件
+	file := c.entryFile(key)
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		logger.Get().Warn("写入总结缓存失败", zap.Error(err))
+		return
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		_ = os.Remove(tmp)
+		logger.Get().Warn("写入总结缓存失败", zap.Error(err))
+	}
+}
+
+// Clear 清空总结缓存
+func (c *Cache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除总结缓存失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// Size 返回缓存条目数
+func (c *Cache) Size() int {
+	files, _ := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	return len(files)
+}
+
+// entryFile 缓存条目对应的文件路径
+func (c *Cache) entryFile(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// cacheKey 计算缓存键: (内容哈希, 模型, 提示词版本, 选项)
+func cacheKey(title, content, model, promptVersion string, opts any) string {
+	contentSum := sha256.Sum256([]byte(title + "\x00" + content))
+	optsJSON, _ := json.Marshal(opts)
+
+	h := sha256.New()
+	for _, part := range []string{hex.EncodeToString(contentSum[:]), model, promptVersion, string(optsJSON)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:40]
+}
@@ -0,0 +1,116 @@
+package summarizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fromsko/krio/internal/config"
+)
+
+func TestCacheKey(t *testing.T) {
+	base := cacheKey("标题", "内容", "glm-4", "v1", map[string]int{"chunk_size": 100})
+
+	tests := []struct {
+		name string
+		key  string
+		same bool
+	}{
+		{"相同输入", cacheKey("标题", "内容", "glm-4", "v1", map[string]int{"chunk_size": 100}), true},
+		{"内容不同", cacheKey("标题", "内容2", "glm-4", "v1", map[string]int{"chunk_size": 100}), false},
+		{"模型不同", cacheKey("标题", "内容", "glm-4.7", "v1", map[string]int{"chunk_size": 100}), false},
+		{"提示词版本不同", cacheKey("标题", "内容", "glm-4", "v2", map[string]int{"chunk_size": 100}), false},
+		{"选项不同", cacheKey("标题", "内容", "glm-4", "v1", map[string]int{"chunk_size": 200}), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.key == base) != tt.same {
+				t.Errorf("cacheKey() same = %v, want %v", tt.key == base, tt.same)
+			}
+		})
+	}
+}
+
+func TestSummarizeCacheHit(t *testing.T) {
+	cache, err := NewCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+
+	// 不创建 LLM: 命中缓存时不应发起调用
+	prompts, err := LoadPrompts("")
+	if err != nil {
+		t.Fatalf("LoadPrompts() error = %v", err)
+	}
+	s := &Summarizer{cfg: &config.ModelConfig{ModelName: "glm-4"}, prompts: prompts, cache: cache}
+	key := s.cacheKey("标题", "正文", Options{Style: StyleDetailed})
+	cache.Set(key, "glm-4", "detailed-test", &Summary{
+		Title:           "缓存的总结",
+		KeyPoints:       []string{"要点"},
+		OriginalContent: "不应持久化",
+	})
+
+	summary, err := s.Summarize(context.Background(), "标题", "正文", Options{})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if summary.Title != "缓存的总结" {
+		t.Errorf("Title = %q, want %q", summary.Title, "缓存的总结")
+	}
+	if summary.OriginalContent != "正文" {
+		t.Errorf("OriginalContent = %q, want %q", summary.OriginalContent, "正文")
+	}
+	if cache.Size() != 1 {
+		t.Errorf("Size() = %d, want 1", cache.Size())
+	}
+
+	// 笔记语言不同时使用不同的缓存键
+	if s.cacheKey("标题", "正文", Options{Style: StyleDetailed, Language: "en"}) == key {
+		t.Error("cacheKey() should differ by language")
+	}
+	if s.cacheKey("标题", "正文", Options{Style: StyleTLDR}) == key {
+		t.Error("cacheKey() should differ by style")
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if _, ok := cache.Get(key); ok {
+		t.Error("Clear() 后仍能读取缓存")
+	}
+}
+
+func TestSummarizeCacheHit_ClassifyWithLLM(t *testing.T) {
+	cache, err := NewCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+	prompts, err := LoadPrompts("")
+	if err != nil {
+		t.Fatalf("LoadPrompts() error = %v", err)
+	}
+
+	// 不创建 LLM: 缓存键按规则结果计算,命中时不应调用 LLM 分类
+	s := &Summarizer{cfg: &config.ModelConfig{ModelName: "glm-4", Style: StyleAuto, ClassifyWithLLM: true}, prompts: prompts, cache: cache}
+	opts, err := s.resolveStyle(Options{ContentType: "article"})
+	if err != nil || !opts.classifyLLM {
+		t.Fatalf("resolveStyle() = %+v, %v, want classifyLLM", opts, err)
+	}
+	key := s.cacheKey("标题", "正文", opts)
+	cache.Set(key, "glm-4", "tutorial-test", &Summary{Title: "缓存的总结", ContentType: "tutorial"})
+
+	summary, err := s.Summarize(context.Background(), "标题", "正文", Options{ContentType: "article"})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if summary.Title != "缓存的总结" || summary.ContentType != "tutorial" {
+		t.Errorf("Summarize() = %q (%s), want cached summary with LLM content type", summary.Title, summary.ContentType)
+	}
+
+	// 不做 LLM 分类时使用不同的缓存键
+	s.cfg.ClassifyWithLLM = false
+	plain, _ := s.resolveStyle(Options{ContentType: "article"})
+	if s.cacheKey("标题", "正文", plain) == key {
+		t.Error("cacheKey() should differ by classify_with_llm")
+	}
+}
@@ -0,0 +1,211 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/pkg/logger"
+	"github.com/tmc/langchaingo/llms/openai"
+	"go.uber.org/zap"
+)
+
+// 触发模型回退的错误类型
+const (
+	ErrorRateLimit = "rate_limit" // 限流 (429)
+	ErrorQuota     = "quota"      // 额度或余额用尽
+	ErrorServer    = "server"     // 服务端错误 (5xx)
+	ErrorTimeout   = "timeout"    // 超时
+	ErrorNetwork   = "network"    // 连接失败等网络错误
+	ErrorAuth      = "auth"       // 鉴权失败 (401/403)
+	ErrorAny       = "any"        // 任意错误 (取消除外)
+)
+
+// ErrorClasses 全部错误类型
+var ErrorClasses = []string{ErrorRateLimit, ErrorQuota, ErrorServer, ErrorTimeout, ErrorNetwork, ErrorAuth, ErrorAny}
+
+// defaultFallbackOn 未配置 fallback_on 时触发回退的错误类型
+// 鉴权失败通常是配置错误,换模型也无济于事,默认不回退
+var defaultFallbackOn = []string{ErrorRateLimit, ErrorQuota, ErrorServer, ErrorTimeout, ErrorNetwork}
+
+// model 一个可调用的模型
+type model struct {
+	name string
+	llm  *openai.LLM
+}
+
+// newModel 创建模型客户端,api_key / base_url 为空时沿用主模型的设置
+func newModel(cfg *config.ModelConfig, endpoint config.ModelEndpoint) (model, error) {
+	apiKey, baseURL := endpoint.APIKey, endpoint.BaseURL
+	if apiKey == "" {
+		apiKey = cfg.APIKey
+	}
+	if baseURL == "" {
+		baseURL = cfg.BaseURL
+	}
+	llm, err := openai.New(
+		openai.WithToken(apiKey),
+		openai.WithBaseURL(baseURL),
+		openai.WithModel(endpoint.ModelName),
+	)
+	if err != nil {
+		return model{}, fmt.Errorf("创建 LLM 失败 (%s): %w", endpoint.ModelName, err)
+	}
+	return model{name: endpoint.ModelName, llm: llm}, nil
+}
+
+// newModels 按顺序创建主模型和备用模型
+func newModels(cfg *config.ModelConfig) ([]model, error) {
+	endpoints := append([]config.ModelEndpoint{{ModelName: cfg.ModelName}}, cfg.Fallbacks...)
+	models := make([]model, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		m, err := newModel(cfg, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, m)
+	}
+	return models, nil
+}
+
+// checkFallbackOn 校验配置的回退错误类型
+func checkFallbackOn(classes []string) error {
+	for _, class := range classes {
+		if !slices.Contains(ErrorClasses, class) {
+			return fmt.Errorf("未知的回退错误类型: %s (可选: %s)", class, strings.Join(ErrorClasses, ", "))
+		}
+	}
+	return nil
+}
+
+// chain 本次调用依次尝试的模型
+// 指定的模型排在最前,其余按配置顺序作为备用
+// 指定的模型不是主模型或备用模型时,需在 allowed_models 中,使用主模型的接口
+func (s *Summarizer) chain(name string) ([]model, error) {
+	if name == "" || name == s.models[0].name {
+		return s.models, nil
+	}
+	if i := slices.IndexFunc(s.models, func(m model) bool { return m.name == name }); i >= 0 {
+		return append([]model{s.models[i]}, slices.Delete(slices.Clone(s.models), i, i+1)...), nil
+	}
+	if !slices.Contains(s.cfg.AllowedModels, name) {
+		return nil, fmt.Errorf("模型 %s 不在配置中,请添加到 model.fallbacks 或 model.allowed_models", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.extra[name]
+	if !ok {
+		var err error
+		if m, err = newModel(s.cfg, config.ModelEndpoint{ModelName: name}); err != nil {
+			return nil, err
+		}
+		if s.extra == nil {
+			s.extra = make(map[string]model)
+		}
+		s.extra[name] = m
+	}
+	return append([]model{m}, s.models...), nil
+}
+
+// call 调用 LLM,失败且错误类型允许回退时依次尝试备用模型
+// 使用 opts.Model 指定的模型,为空时使用主模型;返回实际生成回答的模型
+// 每次调用前检查预算,已用尽时返回 ErrBudgetExceeded (长文分块之间同样会停止)
+func (s *Summarizer) call(ctx context.Context, opts Options, usage *Usage, prompt string) (string, string, error) {
+	if s.BudgetExceeded() {
+		return "", "", ErrBudgetExceeded
+	}
+	models, err := s.chain(opts.Model)
+	if err != nil {
+		return "", "", err
+	}
+
+	var errs []error
+	for i, m := range models {
+		content, err := s.generateWith(ctx, m, usage, prompt, opts.OnToken)
+		if err == nil {
+			return content, m.name, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", m.name, err))
+
+		class := errorClass(err)
+		if i == len(models)-1 || !s.shouldFallback(class) {
+			break
+		}
+		logger.Get().Warn("模型调用失败,切换到备用模型",
+			zap.String("model", m.name),
+			zap.String("fallback", models[i+1].name),
+			zap.String("error_class", class),
+			zap.Error(err),
+		)
+	}
+	return "", "", errors.Join(errs...)
+}
+
+// shouldFallback 该类型的错误是否触发回退
+func (s *Summarizer) shouldFallback(class string) bool {
+	if class == "" {
+		return false
+	}
+	fallbackOn := s.cfg.FallbackOn
+	if len(fallbackOn) == 0 {
+		fallbackOn = defaultFallbackOn
+	}
+	return slices.Contains(fallbackOn, ErrorAny) || slices.Contains(fallbackOn, class)
+}
+
+// statusCode 接口错误信息中的 HTTP 状态码 (如 "API returned unexpected status code: 429")
+var statusCode = regexp.MustCompile(`status code:? (\d{3})`)
+
+// errorClass 判断错误类型
+// 调用方取消时返回空字符串 (不回退);无法归类的错误返回 ErrorAny
+func errorClass(err error) string {
+	if errors.Is(err, context.Canceled) {
+		return ""
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorTimeout
+		}
+		return ErrorNetwork
+	}
+
+	msg := strings.ToLower(err.Error())
+	if m := statusCode.FindStringSubmatch(msg); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		switch {
+		case code == 429 && (strings.Contains(msg, "quota") || strings.Contains(msg, "insufficient")):
+			return ErrorQuota
+		case code == 429:
+			return ErrorRateLimit
+		case code == 402:
+			return ErrorQuota
+		case code == 401 || code == 403:
+			return ErrorAuth
+		case code == 408 || code == 504:
+			return ErrorTimeout
+		case code >= 500:
+			return ErrorServer
+		}
+	}
+	switch {
+	case strings.Contains(msg, "rate limit") || strings.Contains(msg, "too many requests"):
+		return ErrorRateLimit
+	case strings.Contains(msg, "quota") || strings.Contains(msg, "insufficient balance"):
+		return ErrorQuota
+	case strings.Contains(msg, "connection refused") || strings.Contains(msg, "connection reset") ||
+		strings.Contains(msg, "no such host") || strings.Contains(msg, "eof"):
+		return ErrorNetwork
+	}
+	return ErrorAny
+}
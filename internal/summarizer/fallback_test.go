@@ -0,0 +1,185 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/fromsko/krio/internal/config"
+)
+
+const okResponse = `{"id":"1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}],
+"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12}}`
+
+func TestCall_Fallback(t *testing.T) {
+	limited := newTestServer(t, http.StatusTooManyRequests, `{"error":{"message":"rate limit exceeded"}}`)
+	badRequest := newTestServer(t, http.StatusBadRequest, `{"error":{"message":"invalid request"}}`)
+	ok := newTestServer(t, http.StatusOK, okResponse)
+
+	tests := []struct {
+		name       string
+		primary    string
+		fallbacks  []config.ModelEndpoint
+		fallbackOn []string
+		allowed    []string
+		model      string
+		wantModel  string
+		wantErr    bool
+	}{
+		{
+			name:      "限流时回退",
+			primary:   limited,
+			fallbacks: []config.ModelEndpoint{{ModelName: "backup", BaseURL: ok}},
+			wantModel: "backup",
+		},
+		{
+			name:      "请求错误默认不回退",
+			primary:   badRequest,
+			fallbacks: []config.ModelEndpoint{{ModelName: "backup", BaseURL: ok}},
+			wantErr:   true,
+		},
+		{
+			name:       "any 对任意错误回退",
+			primary:    badRequest,
+			fallbacks:  []config.ModelEndpoint{{ModelName: "backup", BaseURL: ok}},
+			fallbackOn: []string{ErrorAny},
+			wantModel:  "backup",
+		},
+		{
+			name:       "未配置的错误类型不回退",
+			primary:    limited,
+			fallbacks:  []config.ModelEndpoint{{ModelName: "backup", BaseURL: ok}},
+			fallbackOn: []string{ErrorServer},
+			wantErr:    true,
+		},
+		{
+			name:      "备用模型全部失败",
+			primary:   limited,
+			fallbacks: []config.ModelEndpoint{{ModelName: "backup", BaseURL: limited}},
+			wantErr:   true,
+		},
+		{
+			name:      "指定备用模型时优先使用",
+			primary:   ok,
+			fallbacks: []config.ModelEndpoint{{ModelName: "backup", BaseURL: ok}},
+			model:     "backup",
+			wantModel: "backup",
+		},
+		{
+			name:      "指定的模型失败时回退到主模型",
+			primary:   ok,
+			fallbacks: []config.ModelEndpoint{{ModelName: "backup", BaseURL: limited}},
+			model:     "backup",
+			wantModel: "primary",
+		},
+		{
+			name:      "指定允许的模型使用主模型接口",
+			primary:   ok,
+			allowed:   []string{"other"},
+			model:     "other",
+			wantModel: "other",
+		},
+		{
+			name:    "指定未配置的模型报错",
+			primary: ok,
+			model:   "unknown",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSummarizer(&config.ModelConfig{
+				APIKey:     "test",
+				BaseURL:    tt.primary,
+				ModelName:  "primary",
+				Fallbacks:  tt.fallbacks,
+				FallbackOn: tt.fallbackOn,
+
+				AllowedModels: tt.allowed,
+			})
+			if err != nil {
+				t.Fatalf("NewSummarizer() error = %v", err)
+			}
+
+			var usage Usage
+			content, model, err := s.call(context.Background(), Options{Model: tt.model}, &usage, "prompt")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("call() = %q, want error", content)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("call() error = %v", err)
+			}
+			if content != "ok" || model != tt.wantModel {
+				t.Errorf("call() = %q, %q, want %q, %q", content, model, "ok", tt.wantModel)
+			}
+		})
+	}
+}
+
+func TestNewSummarizer_InvalidFallbackOn(t *testing.T) {
+	_, err := NewSummarizer(&config.ModelConfig{APIKey: "test", BaseURL: "http://localhost", ModelName: "m", FallbackOn: []string{"sometimes"}})
+	if err == nil {
+		t.Error("NewSummarizer() error = nil, want error for unknown error class")
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{errors.New("API returned unexpected status code: 429: rate limit reached"), ErrorRateLimit},
+		{errors.New("API returned unexpected status code: 429: You exceeded your current quota"), ErrorQuota},
+		{errors.New("API returned unexpected status code: 402: insufficient balance"), ErrorQuota},
+		{errors.New("API returned unexpected status code: 401: invalid api key"), ErrorAuth},
+		{errors.New("API returned unexpected status code: 503: service unavailable"), ErrorServer},
+		{errors.New("API returned unexpected status code: 504: gateway timeout"), ErrorTimeout},
+		{errors.New("API returned unexpected status code: 400: invalid request"), ErrorAny},
+		{fmt.Errorf("post: %w", context.DeadlineExceeded), ErrorTimeout},
+		{fmt.Errorf("post: %w", context.Canceled), ""},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, ErrorNetwork},
+		{errors.New("unexpected EOF"), ErrorNetwork},
+	}
+	for _, tt := range tests {
+		if got := errorClass(tt.err); got != tt.want {
+			t.Errorf("errorClass(%q) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSummarize_FallbackNotCached(t *testing.T) {
+	cache, err := NewCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+	summaryJSON := `{\"title\":\"T\",\"one_sentence\":\"S\",\"key_points\":[\"a\"],\"tags\":[\"x\"]}`
+	s, err := NewSummarizer(&config.ModelConfig{
+		APIKey:    "test",
+		BaseURL:   newTestServer(t, http.StatusTooManyRequests, `{"error":{"message":"rate limit exceeded"}}`),
+		ModelName: "primary",
+		Fallbacks: []config.ModelEndpoint{{ModelName: "backup", BaseURL: newTestServer(t, http.StatusOK,
+			`{"id":"1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"`+summaryJSON+`"},"finish_reason":"stop"}]}`)}},
+	})
+	if err != nil {
+		t.Fatalf("NewSummarizer() error = %v", err)
+	}
+	s.SetCache(cache)
+
+	summary, err := s.Summarize(context.Background(), "标题", "正文", Options{})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if summary.Model != "backup" {
+		t.Errorf("Model = %q, want backup", summary.Model)
+	}
+	if cache.Size() != 0 {
+		t.Errorf("cache.Size() = %d, want 0 (备用模型的总结不应缓存)", cache.Size())
+	}
+}
@@ -0,0 +1,80 @@
+package summarizer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fromsko/krio/internal/lang"
+	"github.com/tidwall/gjson"
+)
+
+// 闪卡类型
+const (
+	CardQA    = "qa"    // 问答卡
+	CardCloze = "cloze" // 填空卡,挖空部分用 ==...== 标出
+)
+
+// Flashcard 闪卡
+type Flashcard struct {
+	Type     string `json:"type"`
+	Question string `json:"question,omitempty"` // 问答卡的问题
+	Answer   string `json:"answer,omitempty"`   // 问答卡的答案
+	Text     string `json:"text,omitempty"`     // 填空卡的句子
+}
+
+// clozePattern 填空卡的挖空标记
+var clozePattern = regexp.MustCompile(`==[^=\n]+==`)
+
+// generateFlashcards 根据内容生成闪卡
+func (s *Summarizer) generateFlashcards(ctx context.Context, title, content string, opts Options, usage *Usage) ([]Flashcard, error) {
+	prompt, err := s.prompts.render(flashcardTemplate, promptData{Title: title, Content: content, Count: opts.Flashcards})
+	if err != nil {
+		return nil, err
+	}
+	prompt += fmt.Sprintf("\n\n语言要求: 卡片使用%s撰写。", lang.Name(opts.language()))
+
+	response, _, err := s.call(ctx, opts, usage, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("LLM 调用失败: %w", err)
+	}
+	return parseFlashcards(response, opts.Flashcards), nil
+}
+
+// parseFlashcards 解析闪卡,丢弃缺少内容或没有挖空的卡片,最多保留 limit 张
+func parseFlashcards(response string, limit int) []Flashcard {
+	var cards []Flashcard
+	seen := make(map[string]bool)
+	forEach(gjson.Get(response, "cards"), func(r gjson.Result) {
+		if len(cards) >= limit {
+			return
+		}
+		card := Flashcard{Type: strings.ToLower(strings.TrimSpace(r.Get("type").String()))}
+		switch card.Type {
+		case CardQA:
+			card.Question = oneLine(r.Get("question").String())
+			card.Answer = oneLine(r.Get("answer").String())
+			if card.Question == "" || card.Answer == "" {
+				return
+			}
+		case CardCloze:
+			card.Text = oneLine(r.Get("text").String())
+			if !clozePattern.MatchString(card.Text) {
+				return
+			}
+		default:
+			return
+		}
+		if key := card.Question + card.Text; !seen[key] {
+			seen[key] = true
+			cards = append(cards, card)
+		}
+	})
+	return cards
+}
+
+// oneLine 将多行文本合并为一行 (闪卡语法以行为单位)
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,171 @@
+package summarizer
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed prompts/*.tmpl
+var builtinPrompts embed.FS
+
+// 总结风格
+const (
+	StyleDetailed  = "detailed"  // 详细学习笔记 (默认)
+	StyleTLDR      = "tldr"      // 极简速览
+	StyleBrief     = "brief"     // 管理层简报
+	StyleTutorial  = "tutorial"  // 教程步骤
+	StylePaper     = "paper"     // 论文评述
+	StyleNews      = "news"      // 新闻摘要
+	StyleReference = "reference" // API 参考速查
+	StyleOpinion   = "opinion"   // 观点评论
+	StyleProduct   = "product"   // 产品调研
+	StyleChangelog = "changelog" // 更新日志
+
+	StyleAuto = "auto" // 按页面类型自动选择
+)
+
+// 内部模板名,不作为总结风格
+const (
+	chunkTemplate     = "chunk"      // 长文分块提炼
+	classifyTemplate  = "classify"   // 页面类型分类
+	schemaTemplate    = "schema"     // 扩展字段说明
+	flashcardTemplate = "flashcards" // 闪卡生成
+)
+
+// isInternal 是否为内部模板
+func isInternal(name string) bool {
+	switch name {
+	case chunkTemplate, classifyTemplate, schemaTemplate, flashcardTemplate:
+		return true
+	}
+	return false
+}
+
+// promptTemplate 单个提示词模板
+type promptTemplate struct {
+	tmpl    *template.Template
+	version string // 风格名 + 模板内容哈希,模板变化时总结缓存自动失效
+}
+
+// Prompts 提示词模板集合
+// 内置模板随程序发布,prompts_dir 中的同名文件 (<风格>.tmpl) 覆盖内置模板,其他文件作为新风格
+type Prompts struct {
+	templates map[string]*promptTemplate
+}
+
+// promptData 提示词模板数据
+type promptData struct {
+	Title   string
+	Content string
+	Index   int // 分块序号 (仅分块模板)
+	Total   int // 分块总数 (仅分块模板)
+	Count   int // 闪卡数量 (仅闪卡模板)
+}
+
+// LoadPrompts 加载内置模板和 dir 中的自定义模板 (dir 为空时只加载内置模板)
+func LoadPrompts(dir string) (*Prompts, error) {
+	p := &Prompts{templates: make(map[string]*promptTemplate)}
+
+	entries, err := builtinPrompts.ReadDir("prompts")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		data, err := builtinPrompts.ReadFile("prompts/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if err := p.add(entry.Name(), string(data)); err != nil {
+			return nil, err
+		}
+	}
+
+	if dir == "" {
+		return p, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("读取提示词模板失败: %w", err)
+		}
+		if err := p.add(filepath.Base(file), string(data)); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// add 解析并注册模板,文件名 (去掉 .tmpl) 即风格名
+func (p *Prompts) add(filename, text string) error {
+	name := strings.TrimSuffix(filename, ".tmpl")
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("解析提示词模板 %s 失败: %w", filename, err)
+	}
+	sum := sha256.Sum256([]byte(text))
+	p.templates[name] = &promptTemplate{
+		tmpl:    tmpl,
+		version: name + "-" + hex.EncodeToString(sum[:])[:8],
+	}
+	return nil
+}
+
+// Styles 可用的总结风格 (已排序)
+func (p *Prompts) Styles() []string {
+	styles := make([]string, 0, len(p.templates))
+	for name := range p.templates {
+		if !isInternal(name) {
+			styles = append(styles, name)
+		}
+	}
+	sort.Strings(styles)
+	return styles
+}
+
+// Has 是否存在该风格
+func (p *Prompts) Has(style string) bool {
+	_, ok := p.templates[style]
+	return ok && !isInternal(style)
+}
+
+// Version 风格对应的提示词版本
+func (p *Prompts) Version(style string) (string, error) {
+	t, err := p.lookup(style)
+	if err != nil {
+		return "", err
+	}
+	return t.version, nil
+}
+
+// lookup 查找模板
+func (p *Prompts) lookup(name string) (*promptTemplate, error) {
+	t, ok := p.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("未知的总结风格: %s (可选: %s)", name, strings.Join(p.Styles(), ", "))
+	}
+	return t, nil
+}
+
+// render 渲染模板
+func (p *Prompts) render(name string, data promptData) (string, error) {
+	t, err := p.lookup(name)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := t.tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("渲染提示词模板 %s 失败: %w", name, err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
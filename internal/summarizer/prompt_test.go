@@ -0,0 +1,115 @@
+package summarizer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fromsko/krio/internal/config"
+)
+
+func TestLoadPrompts_Builtin(t *testing.T) {
+	prompts, err := LoadPrompts("")
+	if err != nil {
+		t.Fatalf("LoadPrompts() error = %v", err)
+	}
+
+	want := []string{StyleBrief, StyleChangelog, StyleDetailed, StyleNews, StyleOpinion, StylePaper, StyleProduct, StyleReference, StyleTLDR, StyleTutorial}
+	if got := strings.Join(prompts.Styles(), ","); got != strings.Join(want, ",") {
+		t.Errorf("Styles() = %s, want %s", got, strings.Join(want, ","))
+	}
+	if prompts.Has(chunkTemplate) || prompts.Has(classifyTemplate) || prompts.Has(schemaTemplate) {
+		t.Error("internal templates should not be styles")
+	}
+
+	for _, style := range want {
+		prompt, err := prompts.render(style, promptData{Title: "标题T", Content: "正文C"})
+		if err != nil {
+			t.Fatalf("render(%s) error = %v", style, err)
+		}
+		for _, s := range []string{"标题T", "正文C", `"key_points"`, "只返回 JSON"} {
+			if !strings.Contains(prompt, s) {
+				t.Errorf("%s prompt missing %q", style, s)
+			}
+		}
+	}
+
+	if schema, err := prompts.render(schemaTemplate, promptData{}); err != nil || !strings.Contains(schema, `"sections"`) {
+		t.Errorf("schema prompt = %q, %v", schema, err)
+	}
+
+	chunk, err := prompts.render(chunkTemplate, promptData{Title: "T", Content: "C", Index: 2, Total: 3})
+	if err != nil || !strings.Contains(chunk, "第 2/3 部分") {
+		t.Errorf("chunk prompt = %q, %v", chunk, err)
+	}
+
+	if _, err := prompts.render("unknown", promptData{}); err == nil || !strings.Contains(err.Error(), "可选") {
+		t.Errorf("render(unknown) error = %v", err)
+	}
+}
+
+func TestLoadPrompts_Override(t *testing.T) {
+	builtin, _ := LoadPrompts("")
+	builtinVersion, _ := builtin.Version(StyleTLDR)
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "tldr.tmpl"), []byte("自定义速览: {{.Title}}"), 0644)
+	os.WriteFile(filepath.Join(dir, "recipe.tmpl"), []byte("菜谱: {{.Content}}"), 0644)
+
+	prompts, err := LoadPrompts(dir)
+	if err != nil {
+		t.Fatalf("LoadPrompts() error = %v", err)
+	}
+
+	if prompt, _ := prompts.render(StyleTLDR, promptData{Title: "T"}); prompt != "自定义速览: T" {
+		t.Errorf("override prompt = %q", prompt)
+	}
+	if version, _ := prompts.Version(StyleTLDR); version == builtinVersion || !strings.HasPrefix(version, "tldr-") {
+		t.Errorf("override version = %q, builtin = %q", version, builtinVersion)
+	}
+	if !prompts.Has("recipe") {
+		t.Error("custom style should be available")
+	}
+
+	os.WriteFile(filepath.Join(dir, "broken.tmpl"), []byte("{{.Title"), 0644)
+	if _, err := LoadPrompts(dir); err == nil {
+		t.Error("LoadPrompts() should fail on invalid template")
+	}
+}
+
+func TestResolveStyle(t *testing.T) {
+	prompts, _ := LoadPrompts("")
+	tests := []struct {
+		name     string
+		cfgStyle string
+		opts     Options
+		want     string
+	}{
+		{"默认详细笔记", "", Options{ContentType: "paper"}, StyleDetailed},
+		{"配置 auto 按页面类型选择", StyleAuto, Options{ContentType: "paper"}, StylePaper},
+		{"auto 未知类型", StyleAuto, Options{}, StyleDetailed},
+		{"视频页", StyleAuto, Options{ContentType: "video"}, StyleTLDR},
+		{"配置指定风格", StyleTLDR, Options{ContentType: "paper"}, StyleTLDR},
+		{"请求指定风格优先", StyleTLDR, Options{Style: StyleNews, ContentType: "paper"}, StyleNews},
+		{"请求指定 auto", StyleTLDR, Options{Style: StyleAuto, ContentType: "changelog"}, StyleChangelog},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Summarizer{cfg: &config.ModelConfig{Style: tt.cfgStyle}, prompts: prompts}
+			opts, err := s.resolveStyle(tt.opts)
+			if err != nil || opts.Style != tt.want {
+				t.Errorf("resolveStyle() = %q, %v, want %q", opts.Style, err, tt.want)
+			}
+			if opts.ContentType != tt.opts.ContentType {
+				t.Errorf("ContentType = %q, want %q", opts.ContentType, tt.opts.ContentType)
+			}
+		})
+	}
+
+	s := &Summarizer{cfg: &config.ModelConfig{}, prompts: prompts}
+	if _, err := s.resolveStyle(Options{Style: "unknown"}); err == nil {
+		t.Error("resolveStyle() should fail on unknown style")
+	}
+}
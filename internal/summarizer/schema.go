@@ -0,0 +1,112 @@
+package summarizer
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// maxSectionDepth 章节最大层数,更深的子章节被丢弃
+const maxSectionDepth = 2
+
+// parseExtended 解析扩展字段
+// 扩展字段均为可选: 类型不符或缺少必需内容的条目直接丢弃,不影响总结本身
+func parseExtended(summary *Summary, response string) {
+	summary.Sections = parseSections(gjson.Get(response, "sections"), 1)
+
+	forEach(gjson.Get(response, "quotes"), func(r gjson.Result) {
+		if text := strings.TrimSpace(r.Get("text").String()); text != "" {
+			summary.Quotes = append(summary.Quotes, Quote{
+				Text:     text,
+				Location: strings.TrimSpace(r.Get("location").String()),
+			})
+		}
+	})
+
+	forEach(gjson.Get(response, "code_snippets"), func(r gjson.Result) {
+		if code := trimCode(r.Get("code").String()); code != "" {
+			summary.CodeSnippets = append(summary.CodeSnippets, CodeSnippet{
+				Language: codeLanguage(r.Get("language").String()),
+				Code:     code,
+				Caption:  strings.TrimSpace(r.Get("caption").String()),
+			})
+		}
+	})
+
+	seen := make(map[string]bool)
+	forEach(gjson.Get(response, "glossary"), func(r gjson.Result) {
+		term := strings.TrimSpace(r.Get("term").String())
+		definition := strings.TrimSpace(r.Get("definition").String())
+		if term == "" || definition == "" || seen[strings.ToLower(term)] {
+			return
+		}
+		seen[strings.ToLower(term)] = true
+		summary.Glossary = append(summary.Glossary, GlossaryTerm{Term: term, Definition: definition})
+	})
+
+	summary.ActionItems = parseStrings(gjson.Get(response, "action_items"))
+	summary.OpenQuestions = parseStrings(gjson.Get(response, "open_questions"))
+}
+
+// parseSections 解析章节,丢弃没有标题或没有内容的章节
+func parseSections(result gjson.Result, depth int) []Section {
+	var sections []Section
+	forEach(result, func(r gjson.Result) {
+		section := Section{
+			Heading: strings.TrimSpace(r.Get("heading").String()),
+			Points:  parseStrings(r.Get("points")),
+		}
+		if depth < maxSectionDepth {
+			section.Subsections = parseSections(r.Get("subsections"), depth+1)
+		}
+		if section.Heading != "" && (len(section.Points) > 0 || len(section.Subsections) > 0) {
+			sections = append(sections, section)
+		}
+	})
+	return sections
+}
+
+// parseStrings 解析字符串数组,丢弃空字符串和非字符串元素
+func parseStrings(result gjson.Result) []string {
+	var items []string
+	forEach(result, func(r gjson.Result) {
+		if r.Type != gjson.String {
+			return
+		}
+		if s := strings.TrimSpace(r.String()); s != "" {
+			items = append(items, s)
+		}
+	})
+	return items
+}
+
+// forEach 遍历数组,非数组时不做任何事
+func forEach(result gjson.Result, fn func(gjson.Result)) {
+	if !result.IsArray() {
+		return
+	}
+	result.ForEach(func(_, r gjson.Result) bool {
+		fn(r)
+		return true
+	})
+}
+
+// trimCode 去掉代码首尾的空行和行尾空白,保留缩进
+func trimCode(code string) string {
+	lines := strings.Split(strings.ReplaceAll(code, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// codeLanguage 规范化代码语言标识 (用于 Markdown 代码块),只保留字母、数字和 +#.-
+func codeLanguage(language string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', strings.ContainsRune("+#.-", r):
+			return r
+		}
+		return -1
+	}, strings.ToLower(strings.TrimSpace(language)))
+}
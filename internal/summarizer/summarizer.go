@@ -3,11 +3,16 @@ package summarizer
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
 
+	"github.com/fromsko/krio/internal/classify"
 	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/internal/lang"
+	"github.com/fromsko/krio/pkg/logger"
 	"github.com/tidwall/gjson"
-	"github.com/tmc/langchaingo/llms"
-	"github.com/tmc/langchaingo/llms/openai"
+	"go.uber.org/zap"
 )
 
 // Summary 总结结果
@@ -15,39 +20,287 @@ type Summary struct {
 	Title           string   `json:"title"`
 	OneSentence     string   `json:"one_sentence"`
 	KeyPoints       []string `json:"key_points"`
+	KeyPointQuotes  []string `json:"key_point_quotes,omitempty"` // 与 key_points 一一对应的原文引用 (保留原文引用时)
 	Tags            []string `json:"tags"`
 	OriginalContent string   `json:"original_content"`
+
+	// 扩展字段 (均可为空)
+	Sections      []Section      `json:"sections,omitempty"`       // 按原文结构组织的章节
+	Quotes        []Quote        `json:"quotes,omitempty"`         // 原文摘录
+	CodeSnippets  []CodeSnippet  `json:"code_snippets,omitempty"`  // 关键代码
+	Glossary      []GlossaryTerm `json:"glossary,omitempty"`       // 术语表
+	ActionItems   []string       `json:"action_items,omitempty"`   // 行动项
+	OpenQuestions []string       `json:"open_questions,omitempty"` // 待解决的问题
+
+	Flashcards []Flashcard `json:"flashcards,omitempty"` // 闪卡 (启用闪卡时)
+	Usage      Usage       `json:"usage"`                // 生成总结消耗的 token 用量
+	incomplete bool        // 可选阶段失败,结果不写入缓存,下次重新生成
+
+	Model         string `json:"model"`          // 生成总结的模型 (回退时为备用模型)
+	Style         string `json:"style"`          // 总结风格
+	ContentType   string `json:"content_type"`   // 页面类型 (如 tutorial、paper)
+	PromptVersion string `json:"prompt_version"` // 提示词版本 (风格名 + 模板哈希)
+}
+
+// Section 章节
+type Section struct {
+	Heading     string    `json:"heading"`
+	Points      []string  `json:"points,omitempty"`
+	Subsections []Section `json:"subsections,omitempty"`
+}
+
+// Quote 原文摘录
+type Quote struct {
+	Text     string `json:"text"`
+	Location string `json:"location,omitempty"` // 所在章节或页码
+}
+
+// CodeSnippet 代码片段
+type CodeSnippet struct {
+	Language string `json:"language,omitempty"`
+	Code     string `json:"code"`
+	Caption  string `json:"caption,omitempty"`
+}
+
+// GlossaryTerm 术语
+type GlossaryTerm struct {
+	Term       string `json:"term"`
+	Definition string `json:"definition"`
+}
+
+// Options 单次总结选项
+type Options struct {
+	NoCache bool   // 跳过总结缓存,强制重新生成 (结果仍会写入缓存)
+	Model   string // 模型名,为空时使用配置的主模型,失败时仍按配置回退到备用模型
+	Style   string // 总结风格 (如 detailed、tldr),为空时使用配置的默认风格,auto 表示按页面类型选择
+
+	ContentType string // 抓取器按规则判断的页面类型,风格为 auto 时据此选择风格
+
+	Language       string // 笔记语言 (如 zh-CN、en),为空时使用简体中文
+	SourceLanguage string // 原文语言,用于判断是否需要保留原文引用
+	KeepQuotes     bool   // 笔记语言与原文不同时,为每个要点附上原文引用
+
+	Flashcards int // 闪卡数量上限,0 表示不生成闪卡
+
+	OnToken func(tokens int) // 设置后流式调用 LLM,每收到一段输出回调本次总结已生成的 token 数
+
+	classifyLLM bool // 风格为 auto 且规则无法判断页面类型,缓存未命中时需调用 LLM 分类 (由 resolveStyle 设置)
+}
+
+// keepQuotes 是否需要为要点附上原文引用
+func (o Options) keepQuotes() bool {
+	return o.KeepQuotes && o.SourceLanguage != "" && !lang.Same(o.language(), o.SourceLanguage)
+}
+
+// language 笔记语言
+func (o Options) language() string {
+	if o.Language == "" {
+		return lang.Default
+	}
+	return o.Language
 }
 
 // Summarizer 总结器
 type Summarizer struct {
-	llm  *openai.LLM
-	cfg  *config.ModelConfig
+	models  []model // 主模型和备用模型
+	cfg     *config.ModelConfig
+	prompts *Prompts
+	cache   *Cache // 总结缓存,为空时不缓存
+
+	mu    sync.Mutex
+	total Usage            // 累计用量
+	extra map[string]model // 按请求指定、不在配置中的模型
 }
 
 // NewSummarizer 创建总结器
 func NewSummarizer(cfg *config.ModelConfig) (*Summarizer, error) {
-	llm, err := openai.New(
-		openai.WithToken(cfg.APIKey),
-		openai.WithBaseURL(cfg.BaseURL),
-		openai.WithModel(cfg.ModelName),
-	)
+	models, err := newModels(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFallbackOn(cfg.FallbackOn); err != nil {
+		return nil, err
+	}
+
+	prompts, err := LoadPrompts(cfg.PromptsDir)
 	if err != nil {
-		return nil, fmt.Errorf("创建 LLM 失败: %w", err)
+		return nil, fmt.Errorf("加载提示词模板失败: %w", err)
+	}
+	if cfg.Style != "" && cfg.Style != StyleAuto && !prompts.Has(cfg.Style) {
+		return nil, fmt.Errorf("未知的总结风格: %s (可选: %s)", cfg.Style, strings.Join(prompts.Styles(), ", "))
 	}
+	checkPrices(cfg, models)
 
 	return &Summarizer{
-		llm: llm,
-		cfg: cfg,
+		models:  models,
+		cfg:     cfg,
+		prompts: prompts,
 	}, nil
 }
 
+// Styles 可用的总结风格 (含 auto)
+func (s *Summarizer) Styles() []string {
+	return append([]string{StyleAuto}, s.prompts.Styles()...)
+}
+
+// style 本次总结使用的风格
+func (s *Summarizer) style(opts Options) string {
+	switch {
+	case opts.Style != "":
+		return opts.Style
+	case s.cfg.Style != "":
+		return s.cfg.Style
+	default:
+		return StyleDetailed
+	}
+}
+
+// resolveStyle 按规则确定本次总结的风格和页面类型
+// 风格为 auto 时按页面类型选择;规则无法判断且启用了 LLM 分类时设置 classifyLLM,由 classifyStyle 在缓存未命中时再分类
+func (s *Summarizer) resolveStyle(opts Options) (Options, error) {
+	opts.Style = s.style(opts)
+	if opts.Style == StyleAuto {
+		opts.classifyLLM = s.cfg.ClassifyWithLLM && (opts.ContentType == "" || opts.ContentType == classify.TypeArticle)
+		opts.Style = classify.Style(opts.ContentType)
+	}
+	if !s.prompts.Has(opts.Style) {
+		return opts, fmt.Errorf("未知的总结风格: %s (可选: %s)", opts.Style, strings.Join(s.Styles(), ", "))
+	}
+	return opts, nil
+}
+
+// classifyStyle 调用 LLM 判断页面类型并重新选择风格,失败时保留规则结果
+func (s *Summarizer) classifyStyle(ctx context.Context, title, content string, opts Options, usage *Usage) Options {
+	contentType, err := s.classify(ctx, title, content, opts, usage)
+	if err != nil {
+		logger.Get().Warn("LLM 页面分类失败,按规则结果选择风格", zap.String("title", title), zap.Error(err))
+		return opts
+	}
+	if contentType != "" {
+		opts.ContentType = contentType
+		opts.Style = classify.Style(contentType)
+	}
+	return opts
+}
+
+// classifySampleRunes LLM 分类只发送正文开头的部分
+const classifySampleRunes = 2000
+
+// classify 调用 LLM 判断页面类型,无法识别回答时返回空字符串
+func (s *Summarizer) classify(ctx context.Context, title, content string, opts Options, usage *Usage) (string, error) {
+	if runes := []rune(content); len(runes) > classifySampleRunes {
+		content = string(runes[:classifySampleRunes])
+	}
+	prompt, err := s.prompts.render(classifyTemplate, promptData{Title: title, Content: content})
+	if err != nil {
+		return "", err
+	}
+	response, _, err := s.call(ctx, opts, usage, prompt)
+	if err != nil {
+		return "", fmt.Errorf("LLM 调用失败: %w", err)
+	}
+	return classify.Parse(response), nil
+}
+
+// SetCache 设置总结缓存
+func (s *Summarizer) SetCache(cache *Cache) {
+	s.cache = cache
+}
+
+// 默认分块大小 (字符数)
+const defaultChunkSize = 12000
+
 // Summarize 总结内容
-func (s *Summarizer) Summarize(ctx context.Context, title, content string) (*Summary, error) {
-	prompt := s.buildPrompt(title, content)
+// 相同内容、模型、提示词版本和选项的总结直接从缓存返回
+// 缓存键按规则判断的页面类型计算,需要 LLM 分类时只在缓存未命中后调用
+// 返回的 Summary.Usage 为本次实际消耗的用量 (缓存命中时为零)
+func (s *Summarizer) Summarize(ctx context.Context, title, content string, opts Options) (*Summary, error) {
+	var usage Usage
+	opts, err := s.resolveStyle(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var key string
+	if s.cache != nil {
+		key = s.cacheKey(title, content, opts)
+		if !opts.NoCache {
+			if summary, ok := s.cache.Get(key); ok {
+				logger.Get().Debug("总结缓存命中", zap.String("title", title))
+				summary.OriginalContent = content
+				// LLM 分类的页面类型随总结一起缓存
+				if !opts.classifyLLM || summary.ContentType == "" {
+					summary.ContentType = opts.ContentType
+				}
+				summary.Usage = usage
+				return summary, nil
+			}
+		}
+	}
+
+	if opts.classifyLLM {
+		opts = s.classifyStyle(ctx, title, content, opts, &usage)
+	}
+	summary, err := s.generate(ctx, title, content, opts, &usage)
+	if err != nil {
+		return nil, err
+	}
+	if s.cache != nil && !summary.incomplete {
+		s.cache.Set(key, summary.Model, summary.PromptVersion, summary)
+	}
+	return summary, nil
+}
+
+// cacheKey 计算当前配置和选项下的总结缓存键
+// opts 需已经过 resolveStyle;需要 LLM 分类时按规则结果的风格计算,并与不分类时的缓存区分
+func (s *Summarizer) cacheKey(title, content string, opts Options) string {
+	version, _ := s.prompts.Version(opts.Style)
+	chunkVersion, _ := s.prompts.Version(chunkTemplate)
+	schemaVersion, _ := s.prompts.Version(schemaTemplate)
+	flashcardVersion := ""
+	if opts.Flashcards > 0 {
+		flashcardVersion, _ = s.prompts.Version(flashcardTemplate)
+	}
+	return cacheKey(title, content, s.modelName(opts), version, struct {
+		Temperature      float64 `json:"temperature"`
+		MaxTokens        int     `json:"max_tokens"`
+		ChunkSize        int     `json:"chunk_size"`
+		ChunkVersion     string  `json:"chunk_version"`
+		SchemaVersion    string  `json:"schema_version"`
+		Language         string  `json:"language"`
+		KeepQuotes       bool    `json:"keep_quotes"`
+		Flashcards       int     `json:"flashcards"`
+		FlashcardVersion string  `json:"flashcard_version"`
+		ClassifyWithLLM  bool    `json:"classify_with_llm,omitempty"`
+	}{s.cfg.Temperature, s.cfg.MaxTokens, s.chunkSize(), chunkVersion, schemaVersion, opts.language(), opts.keepQuotes(),
+		opts.Flashcards, flashcardVersion, opts.classifyLLM})
+}
+
+// generate 调用 LLM 生成总结
+// 内容超过分块大小时,先逐块提炼要点,再基于提炼结果生成总结
+func (s *Summarizer) generate(ctx context.Context, title, content string, opts Options, usage *Usage) (*Summary, error) {
+	input := content
+	fellBack := false
+	if chunks := splitChunks(content, s.chunkSize()); len(chunks) > 1 {
+		condensed, chunkFellBack, err := s.condenseChunks(ctx, title, chunks, opts, usage)
+		if err != nil {
+			return nil, err
+		}
+		input, fellBack = condensed, chunkFellBack
+	}
+
+	prompt, err := s.prompts.render(opts.Style, promptData{Title: title, Content: input})
+	if err != nil {
+		return nil, err
+	}
+	schema, err := s.prompts.render(schemaTemplate, promptData{Title: title})
+	if err != nil {
+		return nil, err
+	}
+	prompt += "\n\n" + schema + languageInstruction(opts)
 
 	// 调用 LLM
-	response, err := llms.GenerateFromSinglePrompt(ctx, s.llm, prompt)
+	response, modelName, err := s.call(ctx, opts, usage, prompt)
 	if err != nil {
 		return nil, fmt.Errorf("LLM 调用失败: %w", err)
 	}
@@ -58,51 +311,114 @@ func (s *Summarizer) Summarize(ctx context.Context, title, content string) (*Sum
 		return nil, fmt.Errorf("解析总结失败: %w", err)
 	}
 
+	// 缓存键按请求的模型计算,备用模型生成的总结不写入缓存,下次仍由请求的模型重新生成
+	if fellBack || modelName != s.modelName(opts) {
+		summary.incomplete = true
+	}
+
+	// 闪卡为可选阶段,失败时只记录警告
+	if opts.Flashcards > 0 {
+		cards, err := s.generateFlashcards(ctx, title, input, opts, usage)
+		if err != nil {
+			logger.Get().Warn("生成闪卡失败", zap.String("title", title), zap.Error(err))
+			summary.incomplete = true
+		}
+		summary.Flashcards = cards
+	}
+
 	summary.OriginalContent = content
+	summary.Model = modelName
+	summary.Style = opts.Style
+	summary.ContentType = opts.ContentType
+	summary.PromptVersion, _ = s.prompts.Version(opts.Style)
+	summary.Usage = *usage
 	return summary, nil
 }
 
-// buildPrompt 构建提示词
-func (s *Summarizer) buildPrompt(title, content string) string {
-	return fmt.Sprintf(`你是一个专业的笔记助手。请深入分析以下网页内容，提取核心知识，重新组织成一份详细且实用的笔记。
-
-网页标题: %s
+// modelName 本次总结指定的模型名
+func (s *Summarizer) modelName(opts Options) string {
+	if opts.Model != "" {
+		return opts.Model
+	}
+	return s.cfg.ModelName
+}
 
-网页内容:
-%s
+// chunkSize 获取分块大小
+func (s *Summarizer) chunkSize() int {
+	if s.cfg.ChunkSize > 0 {
+		return s.cfg.ChunkSize
+	}
+	return defaultChunkSize
+}
 
-请按以下 JSON 格式返回笔记:
-{
-  "title": "文章标题(简洁明了)",
-  "one_sentence": "一句话概括文章核心内容",
-  "key_points": [
-    "章节标题: 详细说明该章节的核心概念和要点",
-    "重要概念: 解释概念的定义、原理和重要性",
-    "关键步骤: 步骤1 -> 步骤2 -> 步骤3，每一步详细说明",
-    "实用技巧: 提取实际应用中的技巧和注意事项",
-    "示例说明: 对示例代码或案例进行详细解释说明",
-    "常见问题: 列出常见问题和解决方案",
-    "总结要点: 归纳总结关键知识点"
-  ],
-  "tags": ["标签1", "标签2", "标签3"]
+// condenseChunks 逐块提炼要点并合并,同时返回是否有分块由备用模型提炼
+func (s *Summarizer) condenseChunks(ctx context.Context, title string, chunks []string, opts Options, usage *Usage) (string, bool, error) {
+	var sb strings.Builder
+	fellBack := false
+	for i, chunk := range chunks {
+		prompt, err := s.prompts.render(chunkTemplate, promptData{Title: title, Content: chunk, Index: i + 1, Total: len(chunks)})
+		if err != nil {
+			return "", false, err
+		}
+		response, modelName, err := s.call(ctx, opts, usage, prompt)
+		if err != nil {
+			return "", false, fmt.Errorf("LLM 调用失败(第 %d/%d 块): %w", i+1, len(chunks), err)
+		}
+		fellBack = fellBack || modelName != s.modelName(opts)
+		sb.WriteString(fmt.Sprintf("## 第 %d 部分\n\n%s\n\n", i+1, strings.TrimSpace(response)))
+	}
+	return sb.String(), fellBack, nil
 }
 
-要求:
-1. title: 提取最合适的标题
-2. one_sentence: 用一句话概括文章的核心价值,不超过50字
-3. key_points: 生成7-15个详细要点，要求:
-   - 不仅仅是简单概括，要提取具体知识点
-   - 保留重要的技术细节、参数说明、代码示例等
-   - 按照逻辑顺序组织（从概念到实践）
-   - 每个要点应该是一到两句话的详细说明
-   - 包含: 核心概念、关键步骤、注意事项、技巧说明等
-   - 对于技术文档，要保留命令、配置项、API说明等重要信息
-   - 对于教程类内容，要保留操作步骤和关键代码
-4. tags: 生成3-5个相关标签
+// splitChunks 按段落将内容切分为不超过 size 个字符的块
+func splitChunks(content string, size int) []string {
+	if size <= 0 || utf8.RuneCountInString(content) <= size {
+		return []string{content}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
 
-目标：生成一份内容丰富、信息完整、可以直接作为学习资料使用的详细笔记。
+	flush := func() {
+		if text := strings.TrimSpace(current.String()); text != "" {
+			chunks = append(chunks, text)
+		}
+		current.Reset()
+		currentLen = 0
+	}
+
+	for _, para := range strings.Split(content, "\n\n") {
+		runes := []rune(para)
+
+		// 单个段落超长时,强制按字符切分
+		for len(runes) > size {
+			flush()
+			chunks = append(chunks, string(runes[:size]))
+			runes = runes[size:]
+		}
+
+		if currentLen+len(runes) > size {
+			flush()
+		}
+		current.WriteString(string(runes))
+		current.WriteString("\n\n")
+		currentLen += len(runes) + 2
+	}
+	flush()
+
+	return chunks
+}
 
-只返回 JSON,不要其他说明文字。`, title, content)
+// languageInstruction 笔记语言要求,附加在提示词末尾
+func languageInstruction(opts Options) string {
+	instruction := fmt.Sprintf("\n\n语言要求: 除 quotes 和 code_snippets 保持原文外,所有字段均使用%s撰写。", lang.Name(opts.language()))
+	if opts.keepQuotes() {
+		instruction += fmt.Sprintf(`
+另外返回 "key_point_quotes" 数组,与 key_points 一一对应,每项是支撑该要点的一句%s原文 (逐字摘录,不要翻译);没有合适原文时填空字符串。`,
+			lang.Name(opts.SourceLanguage))
+	}
+	return instruction
 }
 
 // parseSummary 解析总结结果
@@ -134,6 +450,18 @@ func (s *Summarizer) parseSummary(response string) (*Summary, error) {
 		return true
 	})
 
+	// 提取原文引用 (可选),数量与要点不一致时丢弃
+	var quotes []string
+	if quotesResult := gjson.Get(response, "key_point_quotes"); quotesResult.IsArray() {
+		quotesResult.ForEach(func(_, result gjson.Result) bool {
+			quotes = append(quotes, result.String())
+			return true
+		})
+		if len(quotes) != len(keyPoints) {
+			quotes = nil
+		}
+	}
+
 	// 提取 tags 数组 (可选)
 	var tags []string
 	if tagsResult.Exists() && tagsResult.IsArray() {
@@ -144,11 +472,13 @@ func (s *Summarizer) parseSummary(response string) (*Summary, error) {
 	}
 
 	summary := &Summary{
-		Title:       titleResult.String(),
-		OneSentence: oneSentenceResult.String(),
-		KeyPoints:   keyPoints,
-		Tags:        tags,
+		Title:          titleResult.String(),
+		OneSentence:    oneSentenceResult.String(),
+		KeyPoints:      keyPoints,
+		KeyPointQuotes: quotes,
+		Tags:           tags,
 	}
+	parseExtended(summary, response)
 
 	return summary, nil
 }
@@ -0,0 +1,122 @@
+package summarizer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSummary_Quotes(t *testing.T) {
+	s := &Summarizer{}
+
+	summary, err := s.parseSummary(`{"title":"T","one_sentence":"S","key_points":["a","b"],"key_point_quotes":["quote a",""],"tags":["x"]}`)
+	if err != nil {
+		t.Fatalf("parseSummary failed: %v", err)
+	}
+	if len(summary.KeyPointQuotes) != 2 || summary.KeyPointQuotes[0] != "quote a" {
+		t.Errorf("KeyPointQuotes = %v", summary.KeyPointQuotes)
+	}
+
+	// 数量不一致时丢弃引用
+	summary, err = s.parseSummary(`{"title":"T","one_sentence":"S","key_points":["a","b"],"key_point_quotes":["quote a"]}`)
+	if err != nil {
+		t.Fatalf("parseSummary failed: %v", err)
+	}
+	if summary.KeyPointQuotes != nil {
+		t.Errorf("mismatched quotes should be dropped, got %v", summary.KeyPointQuotes)
+	}
+}
+
+func TestLanguageInstruction(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		want       string
+		wantQuotes bool
+	}{
+		{"默认简体中文", Options{}, "简体中文", false},
+		{"英文笔记", Options{Language: "en", SourceLanguage: "zh"}, "English", false},
+		{"保留原文引用", Options{Language: "zh-CN", SourceLanguage: "en", KeepQuotes: true}, "简体中文", true},
+		{"同语言无需引用", Options{Language: "en", SourceLanguage: "en", KeepQuotes: true}, "English", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := languageInstruction(tt.opts)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("instruction %q missing %q", got, tt.want)
+			}
+			if strings.Contains(got, "key_point_quotes") != tt.wantQuotes {
+				t.Errorf("instruction %q, want quotes = %v", got, tt.wantQuotes)
+			}
+		})
+	}
+}
+
+func TestParseSummary_Extended(t *testing.T) {
+	s := &Summarizer{}
+
+	response := `{
+  "title": "T", "one_sentence": "S", "key_points": ["a"],
+  "sections": [
+    {"heading": "安装", "points": ["下载", "  ", 42], "subsections": [
+      {"heading": "Linux", "points": ["解压"], "subsections": [{"heading": "过深", "points": ["丢弃"]}]}
+    ]},
+    {"heading": "", "points": ["无标题"]},
+    {"heading": "空章节", "points": []}
+  ],
+  "quotes": [{"text": "Less is more.", "location": "第 2 节"}, {"text": " "}],
+  "code_snippets": [{"language": "Go ", "code": "\n\nfunc main() {\n\tfmt.Println(1)   \n}\n\n"}, {"language": "sh", "code": ""}],
+  "glossary": [{"term": "GC", "definition": "垃圾回收"}, {"term": "gc", "definition": "重复"}, {"term": "无定义"}],
+  "action_items": ["升级到 1.22", ""],
+  "open_questions": "不是数组"
+}`
+	summary, err := s.parseSummary(response)
+	if err != nil {
+		t.Fatalf("parseSummary failed: %v", err)
+	}
+
+	if len(summary.Sections) != 1 {
+		t.Fatalf("Sections = %+v", summary.Sections)
+	}
+	install := summary.Sections[0]
+	if install.Heading != "安装" || len(install.Points) != 1 || len(install.Subsections) != 1 {
+		t.Errorf("section = %+v", install)
+	}
+	if sub := install.Subsections[0]; sub.Heading != "Linux" || sub.Subsections != nil {
+		t.Errorf("subsection = %+v, want depth limited to %d", sub, maxSectionDepth)
+	}
+
+	if len(summary.Quotes) != 1 || summary.Quotes[0].Location != "第 2 节" {
+		t.Errorf("Quotes = %+v", summary.Quotes)
+	}
+	if len(summary.CodeSnippets) != 1 || summary.CodeSnippets[0].Language != "go" ||
+		summary.CodeSnippets[0].Code != "func main() {\n\tfmt.Println(1)\n}" {
+		t.Errorf("CodeSnippets = %+v", summary.CodeSnippets)
+	}
+	if len(summary.Glossary) != 1 || summary.Glossary[0].Term != "GC" {
+		t.Errorf("Glossary = %+v", summary.Glossary)
+	}
+	if len(summary.ActionItems) != 1 || summary.OpenQuestions != nil {
+		t.Errorf("ActionItems = %v, OpenQuestions = %v", summary.ActionItems, summary.OpenQuestions)
+	}
+}
+
+func TestParseFlashcards(t *testing.T) {
+	response := `{"cards": [
+  {"type": "qa", "question": "什么是\nGC?", "answer": "垃圾回收"},
+  {"type": "qa", "question": "缺少答案"},
+  {"type": "cloze", "text": "Go 由 ==Google== 开发"},
+  {"type": "cloze", "text": "没有挖空"},
+  {"type": "QA", "question": "什么是 GC?", "answer": "重复"},
+  {"type": "essay", "text": "未知类型"},
+  {"type": "qa", "question": "超出上限", "answer": "x"}
+]}`
+
+	cards := parseFlashcards(response, 3)
+	if len(cards) != 3 {
+		t.Fatalf("parseFlashcards() = %+v", cards)
+	}
+	if cards[0].Question != "什么是 GC?" || cards[1].Text != "Go 由 ==Google== 开发" || cards[2].Question != "超出上限" {
+		t.Errorf("parseFlashcards() = %+v", cards)
+	}
+}
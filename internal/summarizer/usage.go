@@ -0,0 +1,179 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode"
+
+	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/pkg/logger"
+	"github.com/tmc/langchaingo/llms"
+	"go.uber.org/zap"
+)
+
+// ErrBudgetExceeded 累计用量已达到预算 (model.budget),不再调用 LLM
+var ErrBudgetExceeded = errors.New("用量已达到预算")
+
+// Usage token 用量和费用
+type Usage struct {
+	Calls            int     `json:"calls"`               // LLM 调用次数
+	PromptTokens     int     `json:"prompt_tokens"`       // 输入 token 数
+	CompletionTokens int     `json:"completion_tokens"`   // 输出 token 数
+	Cost             float64 `json:"cost"`                // 费用 (按价格表计算,未配置价格时为 0)
+	Estimated        bool    `json:"estimated,omitempty"` // 部分调用未返回用量,使用了本地估算
+}
+
+// TotalTokens 总 token 数
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Add 累加用量
+func (u *Usage) Add(other Usage) {
+	u.Calls += other.Calls
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.Cost += other.Cost
+	u.Estimated = u.Estimated || other.Estimated
+}
+
+// String 用量摘要,如 "3 次调用, 12034 tokens (输入 11000 / 输出 1034), 费用 0.0123"
+func (u Usage) String() string {
+	s := fmt.Sprintf("%d 次调用, %d tokens (输入 %d / 输出 %d)", u.Calls, u.TotalTokens(), u.PromptTokens, u.CompletionTokens)
+	if u.Cost > 0 {
+		s += fmt.Sprintf(", 费用 %.4f", u.Cost)
+	}
+	if u.Estimated {
+		s += " (含估算)"
+	}
+	return s
+}
+
+// generateWith 调用指定模型,用量累加到 usage 和总结器的累计用量
+// 调用失败时按估算的输入 token (及流式已收到的输出) 计入,避免预算少算
+// onToken 不为空时流式生成,每收到一段输出回调已生成的 token 数
+// (usage 中已有的输出 token 加上本次调用逐段累加的估算值,回退到下一个模型时重新计数)
+func (s *Summarizer) generateWith(ctx context.Context, m model, usage *Usage, prompt string, onToken func(int)) (string, error) {
+	var options []llms.CallOption
+	streamed := 0
+	if onToken != nil {
+		options = append(options, llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+			if len(chunk) > 0 {
+				streamed += EstimateTokens(string(chunk))
+				onToken(usage.CompletionTokens + streamed)
+			}
+			return nil
+		}))
+	}
+	resp, err := m.llm.GenerateContent(ctx, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
+	}, options...)
+	if err == nil && len(resp.Choices) == 0 {
+		err = fmt.Errorf("模型返回为空")
+	}
+	if err != nil {
+		u := Usage{
+			Calls:            1,
+			PromptTokens:     EstimateTokens(prompt),
+			CompletionTokens: streamed,
+			Estimated:        true,
+		}
+		u.Cost = price(s.cfg, m.name, u)
+		s.record(usage, u)
+		return "", err
+	}
+	choice := resp.Choices[0]
+
+	u := Usage{
+		Calls:            1,
+		PromptTokens:     intInfo(choice.GenerationInfo, "PromptTokens"),
+		CompletionTokens: intInfo(choice.GenerationInfo, "CompletionTokens"),
+	}
+	// 部分兼容接口不返回用量,按本地估算
+	if u.PromptTokens == 0 && u.CompletionTokens == 0 {
+		u.PromptTokens = EstimateTokens(prompt)
+		u.CompletionTokens = EstimateTokens(choice.Content)
+		u.Estimated = true
+	}
+	u.Cost = price(s.cfg, m.name, u)
+
+	s.record(usage, u)
+	return choice.Content, nil
+}
+
+// record 将一次调用的用量累加到 usage 和总结器的累计用量
+func (s *Summarizer) record(usage *Usage, u Usage) {
+	usage.Add(u)
+	s.mu.Lock()
+	s.total.Add(u)
+	s.mu.Unlock()
+}
+
+// TotalUsage 总结器创建以来的累计用量 (失败的调用按估算的输入 token 计入)
+func (s *Summarizer) TotalUsage() Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.total
+}
+
+// BudgetExceeded 累计用量是否已达到配置的预算
+func (s *Summarizer) BudgetExceeded() bool {
+	budget := s.cfg.Budget
+	usage := s.TotalUsage()
+	return (budget.MaxTokens > 0 && usage.TotalTokens() >= budget.MaxTokens) ||
+		(budget.MaxCost > 0 && usage.Cost >= budget.MaxCost)
+}
+
+// checkPrices 配置了费用预算但模型没有价格时记录警告 (该模型的费用按 0 计算,费用预算对其不生效)
+func checkPrices(cfg *config.ModelConfig, models []model) {
+	if cfg.Budget.MaxCost <= 0 {
+		return
+	}
+	var names []string
+	for _, m := range models {
+		names = append(names, m.name)
+	}
+	names = append(names, cfg.AllowedModels...)
+	for _, name := range names {
+		if _, ok := cfg.Prices[name]; !ok {
+			logger.Get().Warn("已设置费用预算,但模型未配置价格,其费用按 0 计算", zap.String("model", name), zap.Float64("max_cost", cfg.Budget.MaxCost))
+		}
+	}
+}
+
+// price 按价格表计算费用,价格单位为每百万 token
+func price(cfg *config.ModelConfig, model string, u Usage) float64 {
+	p, ok := cfg.Prices[model]
+	if !ok {
+		return 0
+	}
+	return (float64(u.PromptTokens)*p.Input + float64(u.CompletionTokens)*p.Output) / 1e6
+}
+
+// intInfo 读取 GenerationInfo 中的整数字段
+func intInfo(info map[string]any, key string) int {
+	switch v := info[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// EstimateTokens 本地估算 token 数
+// 中日韩文字约 1 字 1 token,其他文字约 4 个字符 1 token
+func EstimateTokens(text string) int {
+	cjk, other := 0, 0
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
@@ -0,0 +1,175 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/fromsko/krio/internal/config"
+)
+
+// newTestServer 创建模拟 OpenAI 接口,固定返回 status 和 body
+func newTestServer(t *testing.T, status int, body string) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// newTestSummarizer 创建连接到模拟 OpenAI 接口的总结器
+func newTestSummarizer(t *testing.T, body string) *Summarizer {
+	t.Helper()
+	s, err := NewSummarizer(&config.ModelConfig{
+		APIKey:    "test",
+		BaseURL:   newTestServer(t, http.StatusOK, body),
+		ModelName: "test-model",
+		Prices:    map[string]config.ModelPrice{"test-model": {Input: 2, Output: 8}},
+	})
+	if err != nil {
+		t.Fatalf("NewSummarizer() error = %v", err)
+	}
+	return s
+}
+
+func TestCall_Usage(t *testing.T) {
+	s := newTestSummarizer(t, `{"id":"1","object":"chat.completion","model":"test-model",
+"choices":[{"index":0,"message":{"role":"assistant","content":"tutorial"},"finish_reason":"stop"}],
+"usage":{"prompt_tokens":1000,"completion_tokens":500,"total_tokens":1500}}`)
+
+	var usage Usage
+	for range 2 {
+		if _, _, err := s.call(context.Background(), Options{}, &usage, "prompt"); err != nil {
+			t.Fatalf("call() error = %v", err)
+		}
+	}
+	if usage.Calls != 2 || usage.PromptTokens != 2000 || usage.CompletionTokens != 1000 || usage.Estimated {
+		t.Errorf("usage = %+v", usage)
+	}
+	// (2000 * 2 + 1000 * 8) / 1e6
+	if usage.Cost < 0.011999 || usage.Cost > 0.012001 {
+		t.Errorf("cost = %f, want 0.012", usage.Cost)
+	}
+	if total := s.TotalUsage(); total != usage {
+		t.Errorf("TotalUsage() = %+v, want %+v", total, usage)
+	}
+}
+
+func TestCall_EstimatedUsage(t *testing.T) {
+	s := newTestSummarizer(t, `{"id":"1","object":"chat.completion","model":"test-model",
+"choices":[{"index":0,"message":{"role":"assistant","content":"你好世界"},"finish_reason":"stop"}]}`)
+
+	var usage Usage
+	if _, _, err := s.call(context.Background(), Options{}, &usage, "总结这段文字 abcdefgh"); err != nil {
+		t.Fatalf("call() error = %v", err)
+	}
+	if !usage.Estimated || usage.PromptTokens != EstimateTokens("总结这段文字 abcdefgh") || usage.CompletionTokens != 4 {
+		t.Errorf("usage = %+v", usage)
+	}
+}
+
+func TestCall_FailedUsage(t *testing.T) {
+	s, err := NewSummarizer(&config.ModelConfig{
+		APIKey:    "test",
+		BaseURL:   newTestServer(t, http.StatusBadRequest, `{"error":{"message":"bad request"}}`),
+		ModelName: "test-model",
+		Prices:    map[string]config.ModelPrice{"test-model": {Input: 2, Output: 8}},
+	})
+	if err != nil {
+		t.Fatalf("NewSummarizer() error = %v", err)
+	}
+
+	var usage Usage
+	if _, _, err := s.call(context.Background(), Options{}, &usage, "总结这段文字 abcdefgh"); err == nil {
+		t.Fatal("call() should fail")
+	}
+	want := Usage{Calls: 1, PromptTokens: EstimateTokens("总结这段文字 abcdefgh"), Estimated: true}
+	want.Cost = float64(want.PromptTokens) * 2 / 1e6
+	if usage != want {
+		t.Errorf("usage = %+v, want %+v", usage, want)
+	}
+	if total := s.TotalUsage(); total != want {
+		t.Errorf("TotalUsage() = %+v, want %+v", total, want)
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	for text, want := range map[string]int{
+		"":             0,
+		"你好世界":         4,
+		"hello world":  3,
+		"Go 语言":        3,
+		"こんにちは, 안녕하세요": 10 + 1,
+	} {
+		if got := EstimateTokens(text); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestCall_Streaming(t *testing.T) {
+	chunk := func(content string) string {
+		return `data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"` + content + `"}}]}` + "\n\n"
+	}
+	s := newTestSummarizer(t, chunk("你好")+chunk(",")+chunk("世界")+
+		`data: {"id":"1","object":"chat.completion.chunk","choices":[],"usage":{"prompt_tokens":5,"completion_tokens":3,"total_tokens":8}}`+"\n\n"+
+		"data: [DONE]\n\n")
+
+	var progress []int
+	usage := Usage{CompletionTokens: 10}
+	content, _, err := s.call(context.Background(), Options{OnToken: func(n int) { progress = append(progress, n) }}, &usage, "prompt")
+	if err != nil {
+		t.Fatalf("call() error = %v", err)
+	}
+	if content != "你好,世界" {
+		t.Errorf("content = %q, want %q", content, "你好,世界")
+	}
+	want := []int{10 + 2, 10 + 2 + 1, 10 + 2 + 1 + 2}
+	if !slices.Equal(progress, want) {
+		t.Errorf("progress = %v, want %v", progress, want)
+	}
+	if usage.PromptTokens != 5 || usage.CompletionTokens != 13 || usage.Estimated {
+		t.Errorf("usage = %+v", usage)
+	}
+}
+
+func TestSummarize_BudgetBetweenChunks(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1","object":"chat.completion","model":"test-model",
+"choices":[{"index":0,"message":{"role":"assistant","content":"要点"},"finish_reason":"stop"}],
+"usage":{"prompt_tokens":800,"completion_tokens":200,"total_tokens":1000}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	s, err := NewSummarizer(&config.ModelConfig{
+		APIKey:    "test",
+		BaseURL:   server.URL,
+		ModelName: "test-model",
+		ChunkSize: 10,
+		Budget:    config.BudgetConfig{MaxTokens: 1000},
+	})
+	if err != nil {
+		t.Fatalf("NewSummarizer() error = %v", err)
+	}
+
+	// 三个分块: 第一块用完预算后不再调用
+	_, err = s.Summarize(context.Background(), "标题", "第一段内容很长很长\n\n第二段内容很长很长\n\n第三段内容很长很长", Options{})
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("Summarize() error = %v, want ErrBudgetExceeded", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if !s.BudgetExceeded() || s.TotalUsage().TotalTokens() != 1000 {
+		t.Errorf("TotalUsage() = %+v, want budget exceeded at 1000 tokens", s.TotalUsage())
+	}
+}
@@ -0,0 +1,38 @@
+package tool
+
+// Stage 单个 URL 的处理阶段
+type Stage string
+
+// 处理阶段,按顺序推进;命中缓存或跳过总结时部分阶段不会出现
+const (
+	StageFetching    Stage = "fetching"    // 请求网页
+	StageExtracting  Stage = "extracting"  // 收到响应,提取正文
+	StageSummarizing Stage = "summarizing" // 调用 LLM 总结
+	StageSaving      Stage = "saving"      // 生成并保存笔记
+	StageDone        Stage = "done"        // 处理结束 (成功、跳过或失败)
+)
+
+// ProgressFunc 进度回调
+// tokens 为总结阶段已生成的 token 数,其他阶段为 0;批量抓取时会从多个协程并发调用
+type ProgressFunc func(url string, stage Stage, tokens int)
+
+// SetProgress 设置进度回调,设置后总结使用流式生成以便报告已生成的 token 数
+// 需在开始处理前设置
+func (t *SaveWebNoteTool) SetProgress(fn ProgressFunc) {
+	t.progress = fn
+	hook := func(url string) { fn(url, StageExtracting, 0) }
+	if fn == nil {
+		hook = nil
+	}
+	t.fetcher.SetExtractHook(hook)
+	if t.cachedFetcher != nil {
+		t.cachedFetcher.SetExtractHook(hook)
+	}
+}
+
+// report 报告进度,未设置回调时不做任何事
+func (t *SaveWebNoteTool) report(url string, stage Stage, tokens int) {
+	if t.progress != nil {
+		t.progress(url, stage, tokens)
+	}
+}
@@ -2,13 +2,17 @@ package tool
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/fromsko/krio/internal/config"
+	"github.com/fromsko/krio/internal/lang"
 	"github.com/fromsko/krio/internal/note"
 	"github.com/fromsko/krio/internal/obsidian"
+	"github.com/fromsko/krio/internal/policy"
 	"github.com/fromsko/krio/internal/scraper"
 	"github.com/fromsko/krio/internal/summarizer"
+	"github.com/fromsko/krio/internal/urlnorm"
 	"github.com/fromsko/krio/pkg/logger"
 	"go.uber.org/zap"
 )
@@ -18,6 +22,12 @@ type SaveWebNoteRequest struct {
 	URL    string   `json:"url" jsonschema:"description=要保存的网页URL,required"`
 	Tags   []string `json:"tags,omitempty" jsonschema:"description=自定义标签列表,可选"`
 	Folder string   `json:"folder,omitempty" jsonschema:"description=保存到Obsidian的文件夹,可选"`
+
+	NoSummaryCache bool   `json:"no_summary_cache,omitempty" jsonschema:"description=忽略总结缓存并重新生成,可选"`
+	Force          bool   `json:"force,omitempty" jsonschema:"description=即使内容未变化或配置为跳过变化的页面也重新生成笔记,可选"`
+	Language       string `json:"language,omitempty" jsonschema:"description=笔记语言,如 zh-CN、en,source 表示与原文相同,可选"`
+	Style          string `json:"style,omitempty" jsonschema:"description=总结风格: auto (按页面类型选择)、detailed、tldr、brief、tutorial、reference、paper、news、opinion、product、changelog,可选"`
+	Model          string `json:"model,omitempty" jsonschema:"description=本次使用的模型名,覆盖配置的主模型 (失败时仍按配置回退),可选"`
 }
 
 // SaveWebNoteResponse 保存网页笔记响应
@@ -28,16 +38,44 @@ type SaveWebNoteResponse struct {
 	FilePath  string `json:"file_path,omitempty"`
 	Content   string `json:"content,omitempty"`
 	NoteCount int    `json:"note_count"`
+	Status    string `json:"status,omitempty"`  // 抓取状态: new/cached/unchanged/changed/duplicate/rejected/budget_exceeded
+	Skipped   bool   `json:"skipped,omitempty"` // 内容未变化等原因跳过了总结
+	Partial   bool   `json:"partial,omitempty"` // 正文可能不完整 (登录墙/付费墙)
+
+	Usage *summarizer.Usage `json:"usage,omitempty"` // 本次总结的 token 用量和费用 (命中总结缓存时只含分类调用)
+}
+
+// 抓取状态之外的响应状态
+const (
+	StatusDuplicate = "duplicate"       // 批量请求中的重复 URL
+	StatusRejected  = "rejected"        // 被过滤规则拒绝
+	StatusBudget    = "budget_exceeded" // 预算已用尽,未处理
+)
+
+// fetchFailure 抓取失败的响应,被过滤规则拒绝时标记为 rejected
+func fetchFailure(err error) SaveWebNoteResponse {
+	resp := SaveWebNoteResponse{
+		Success: false,
+		Message: fmt.Sprintf("抓取网页失败: %v", err),
+	}
+	if errors.Is(err, policy.ErrRejected) {
+		resp.Message = err.Error()
+		resp.Status = StatusRejected
+	}
+	return resp
 }
 
 // SaveWebNoteTool 保存网页笔记工具
 type SaveWebNoteTool struct {
-	cfg        *config.Config
-	fetcher    *scraper.Fetcher
-	cachedFetcher    *scraper.CachedFetcher // 带缓存的抓取器
-	summarizer *summarizer.Summarizer
-	generator  *note.Generator
-	obsidian   *obsidian.Client // Obsidian MCP 客户端
+	cfg           *config.Config
+	fetcher       *scraper.Fetcher
+	cachedFetcher *scraper.CachedFetcher // 带缓存的抓取器
+	summarizer    *summarizer.Summarizer
+	summaryCache  *summarizer.Cache // 总结缓存
+	generator     *note.Generator
+	obsidian      *obsidian.Client          // Obsidian MCP 客户端
+	attachments   *obsidian.AttachmentStore // 图片附件存储
+	progress      ProgressFunc              // 进度回调,为空时不报告
 }
 
 // NewSaveWebNoteTool 创建工具
@@ -59,10 +97,24 @@ func NewSaveWebNoteTool(ctx context.Context, cfg *config.Config) (*SaveWebNoteTo
 		)
 	}
 
+	// 总结缓存与网页缓存共用缓存根目录
+	var summaryCache *summarizer.Cache
+	if cfg.Scraper.EnableCache {
+		c, err := summarizer.NewCache(scraper.SummaryCacheDir(&cfg.Scraper))
+		if err != nil {
+			logger.Get().Warn("创建总结缓存失败,每次都将重新生成总结", zap.Error(err))
+		} else {
+			summaryCache = c
+		}
+	}
+
 	summarizer, err := summarizer.NewSummarizer(&cfg.Model)
 	if err != nil {
 		return nil, fmt.Errorf("创建总结器失败: %w", err)
 	}
+	if summaryCache != nil {
+		summarizer.SetCache(summaryCache)
+	}
 
 	generator := note.NewGenerator(&cfg.Note)
 
@@ -73,13 +125,24 @@ func NewSaveWebNoteTool(ctx context.Context, cfg *config.Config) (*SaveWebNoteTo
 		// 不返回错误,继续创建工具
 	}
 
+	// 创建附件存储 (图片和 HTML 快照)
+	var attachments *obsidian.AttachmentStore
+	if cfg.Note.Images.Enabled || (cfg.Note.Archive.Enabled && cfg.Note.Archive.HTMLSnapshot) {
+		attachments, err = obsidian.NewAttachmentStore(&cfg.ObsidianMCP)
+		if err != nil {
+			logger.Get().Warn("创建附件存储失败,笔记将不包含图片和快照", zap.Error(err))
+		}
+	}
+
 	return &SaveWebNoteTool{
-		cfg:        cfg,
-		fetcher:    fetcher,
+		cfg:           cfg,
+		fetcher:       fetcher,
 		cachedFetcher: cachedFetcher,
-		summarizer: summarizer,
-		generator:  generator,
-		obsidian:   obsidianClient,
+		summarizer:    summarizer,
+		summaryCache:  summaryCache,
+		generator:     generator,
+		obsidian:      obsidianClient,
+		attachments:   attachments,
 	}, nil
 }
 
@@ -93,61 +156,191 @@ func (t *SaveWebNoteTool) SaveWebNote(ctx context.Context, req SaveWebNoteReques
 		zap.String("folder", req.Folder),
 	)
 
-	// 1. 抓取网页内容
-	log.Debug("抓取网页内容", zap.String("url", req.URL))
-	var page *scraper.WebPage
-	var err error
+	defer t.report(req.URL, StageDone, 0)
 
-	// 优先使用缓存抓取器
-	if t.cachedFetcher != nil {
-		page, err = t.cachedFetcher.Fetch(req.URL)
-	} else {
-		page, err = t.fetcher.Fetch(req.URL)
+	if t.summarizer.BudgetExceeded() {
+		log.Warn("预算已用尽,跳过", zap.String("url", req.URL), zap.Stringer("usage", t.summarizer.TotalUsage()))
+		return budgetSkipped(), nil
 	}
 
+	// 1. 抓取网页内容
+	log.Debug("抓取网页内容", zap.String("url", req.URL))
+	t.report(req.URL, StageFetching, 0)
+	page, status, err := t.fetchPage(req.URL)
 	if err != nil {
 		log.Error("抓取网页失败", zap.String("url", req.URL), zap.Error(err))
-		return SaveWebNoteResponse{
-			Success: false,
-			Message: fmt.Sprintf("抓取网页失败: %v", err),
-		}, err
+		return fetchFailure(err), err
 	}
 
 	log.Info("网页抓取成功",
 		zap.String("title", page.Title),
+		zap.String("content_type", page.ContentType),
+		zap.String("page_type", page.PageType),
+		zap.String("status", string(status)),
 		zap.Int("content_length", len(page.Content)),
 	)
 
+	resp, err := t.processPage(ctx, page, status, req)
+	if err != nil {
+		return resp, err
+	}
+	if !resp.Skipped {
+		resp.NoteCount = 1
+	}
+	return resp, nil
+}
+
+// fetchPage 抓取网页 (优先使用缓存抓取器)
+func (t *SaveWebNoteTool) fetchPage(url string) (*scraper.WebPage, scraper.FetchStatus, error) {
+	if t.cachedFetcher != nil {
+		return t.cachedFetcher.FetchWithStatus(url)
+	}
+	page, err := t.fetcher.Fetch(url)
+	return page, scraper.StatusNew, err
+}
+
+// processPage 对已抓取的页面进行总结、生成笔记并保存
+func (t *SaveWebNoteTool) processPage(ctx context.Context, page *scraper.WebPage, status scraper.FetchStatus, req SaveWebNoteRequest) (SaveWebNoteResponse, error) {
+	log := logger.Get()
+
+	// 变化检测: 已生成过笔记、内容和生成选项都未变化时跳过 LLM
+	// Force 或 NoSummaryCache 时总是重新生成;内容未变化而选项不同时更新原笔记
+	var updatePath string
+	noteOpts := t.noteOptions(req)
+	if t.cachedFetcher != nil {
+		if notePath, noteHash, opts, ok := t.cachedFetcher.NoteRecord(page.URL); ok {
+			// 旧版本的记录没有选项摘要,视为相同
+			sameOpts := opts == "" || opts == noteOpts
+			switch {
+			case noteHash == page.ContentHash && (req.Force || req.NoSummaryCache || !sameOpts):
+				log.Info("内容未变化,按请求重新生成", zap.String("url", page.URL), zap.String("file_path", notePath))
+				updatePath = notePath
+			case noteHash == page.ContentHash:
+				log.Info("内容未变化,跳过总结", zap.String("url", page.URL), zap.String("file_path", notePath))
+				return SaveWebNoteResponse{
+					Success:  true,
+					Message:  "内容未变化,已跳过",
+					Title:    page.Title,
+					FilePath: notePath,
+					Status:   string(status),
+					Skipped:  true,
+				}, nil
+			case req.Force && t.cfg.Note.OnChange == "skip":
+				log.Info("内容已变化,按请求更新原笔记", zap.String("url", page.URL), zap.String("file_path", notePath))
+				updatePath = notePath
+			case t.cfg.Note.OnChange == "skip":
+				log.Info("内容已变化,按配置跳过", zap.String("url", page.URL))
+				return SaveWebNoteResponse{
+					Success:  true,
+					Message:  "内容已变化,按配置跳过",
+					Title:    page.Title,
+					FilePath: notePath,
+					Status:   string(scraper.StatusChanged),
+					Skipped:  true,
+				}, nil
+			case t.cfg.Note.OnChange == "new":
+				log.Info("内容已变化,生成新笔记", zap.String("url", page.URL))
+			default:
+				log.Info("内容已变化,更新原笔记", zap.String("url", page.URL), zap.String("file_path", notePath))
+				updatePath = notePath
+			}
+		}
+	}
+
 	// 2. AI 总结
-	log.Debug("开始 AI 总结")
-	summary, err := t.summarizer.Summarize(ctx, page.Title, page.Content)
+	if page.Partial {
+		log.Warn("正文可能不完整,笔记将标记为 partial", zap.String("url", page.URL), zap.String("reason", page.PartialReason))
+	}
+	log.Debug("开始 AI 总结", zap.String("url", page.URL))
+	sourceLang := page.Language
+	if !lang.Valid(sourceLang) {
+		sourceLang = lang.Detect(page.Content)
+	}
+	noteLang := lang.Resolve(t.noteLanguage(req), sourceLang)
+
+	t.report(req.URL, StageSummarizing, 0)
+	var onToken func(int)
+	if t.progress != nil {
+		onToken = func(tokens int) { t.report(req.URL, StageSummarizing, tokens) }
+	}
+	summary, err := t.summarizer.Summarize(ctx, page.Title, page.Content, summarizer.Options{
+		NoCache:        req.NoSummaryCache,
+		Model:          req.Model,
+		Style:          req.Style,
+		ContentType:    page.PageType,
+		Language:       noteLang,
+		SourceLanguage: sourceLang,
+		KeepQuotes:     t.cfg.Note.KeepOriginalQuotes,
+		Flashcards:     t.flashcardCount(),
+		OnToken:        onToken,
+	})
+	if errors.Is(err, summarizer.ErrBudgetExceeded) {
+		// 长文分块之间预算用尽,已消耗的用量计入累计用量
+		log.Warn("总结过程中预算用尽,跳过", zap.String("url", page.URL), zap.Stringer("usage", t.summarizer.TotalUsage()))
+		resp := budgetSkipped()
+		resp.Title = page.Title
+		return resp, nil
+	}
 	if err != nil {
-		log.Error("AI 总结失败", zap.Error(err))
+		log.Error("AI 总结失败", zap.String("url", page.URL), zap.Error(err))
 		return SaveWebNoteResponse{
 			Success: false,
 			Message: fmt.Sprintf("AI 总结失败: %v", err),
+			Title:   page.Title,
 		}, err
 	}
 
 	log.Info("AI 总结成功",
 		zap.String("title", summary.Title),
+		zap.String("model", summary.Model),
 		zap.Int("key_points", len(summary.KeyPoints)),
+		zap.Int("sections", len(summary.Sections)),
+		zap.Int("flashcards", len(summary.Flashcards)),
 	)
 
 	// 3. 生成 Markdown 笔记
 	log.Debug("生成 Markdown 笔记")
-	markdown := t.generator.Generate(summary, page.URL)
-
-	// 4. 保存到 Obsidian (通过 MCP)
+	t.report(req.URL, StageSaving, 0)
 	filename := note.GenerateFilename(summary.Title)
 	folder := t.getFolder(req.Folder)
+	meta := &note.Meta{
+		PageCount:    page.PageCount,
+		FetchedAt:    page.FetchedAt,
+		ArchivedFrom: page.ArchivedFrom,
+		ArchivedAt:   page.ArchivedAt,
+
+		Partial:       page.Partial,
+		PartialReason: page.PartialReason,
+
+		Language:       noteLang,
+		SourceLanguage: sourceLang,
+	}
+	if t.cfg.Note.Images.Enabled && t.attachments != nil {
+		meta.LeadImage, meta.Images = t.saveImages(page)
+	}
+	if t.cfg.Note.Archive.Enabled {
+		t.archivePage(ctx, page, filename, folder, meta)
+	}
+	markdown := t.generator.Generate(summary, page.SourceURL(), meta)
+
+	// 4. 保存到 Obsidian (通过 MCP)
 	var filePath string
 
 	if t.obsidian != nil {
-		log.Info("保存笔记到 Obsidian")
-		actualPath, err := t.obsidian.SaveNote(ctx, markdown, filename, folder)
+		var actualPath string
+		var err error
+		if updatePath != "" {
+			actualPath, err = t.obsidian.UpdateNote(ctx, markdown, updatePath)
+			if err != nil {
+				log.Warn("更新原笔记失败,改为生成新笔记", zap.String("file_path", updatePath), zap.Error(err))
+			}
+		}
+		if actualPath == "" {
+			log.Info("保存笔记到 Obsidian")
+			actualPath, err = t.obsidian.SaveNote(ctx, markdown, filename, folder)
+		}
 		if err != nil {
-			log.Error("保存到 Obsidian 失败", zap.Error(err))
+			log.Error("保存到 Obsidian 失败", zap.String("url", page.URL), zap.Error(err))
 			// 返回错误,但不影响笔记内容的返回
 			return SaveWebNoteResponse{
 				Success: false,
@@ -157,6 +350,11 @@ func (t *SaveWebNoteTool) SaveWebNote(ctx context.Context, req SaveWebNoteReques
 			}, err
 		}
 		filePath = actualPath
+
+		// 记录笔记,下次内容未变化时跳过
+		if t.cachedFetcher != nil {
+			t.cachedFetcher.MarkNoted(page.URL, filePath, page.ContentHash, noteOpts)
+		}
 	} else {
 		// 如果没有 Obsidian 客户端,返回预期的路径
 		filePath = fmt.Sprintf("%s/%s.md", folder, filename)
@@ -167,18 +365,157 @@ func (t *SaveWebNoteTool) SaveWebNote(ctx context.Context, req SaveWebNoteReques
 		zap.String("title", summary.Title),
 		zap.String("file_path", filePath),
 		zap.Int("content_length", len(markdown)),
+		zap.Int("prompt_tokens", summary.Usage.PromptTokens),
+		zap.Int("completion_tokens", summary.Usage.CompletionTokens),
+		zap.Float64("cost", summary.Usage.Cost),
 	)
 
 	return SaveWebNoteResponse{
-		Success:   true,
-		Message:   "笔记保存成功",
-		Title:     summary.Title,
-		FilePath:  filePath,
-		Content:   markdown,
-		NoteCount: 1,
+		Success:  true,
+		Message:  "笔记保存成功",
+		Title:    summary.Title,
+		FilePath: filePath,
+		Content:  markdown,
+		Status:   string(status),
+		Partial:  page.Partial,
+		Usage:    &summary.Usage,
 	}, nil
 }
 
+// saveImages 下载页面图片并保存为附件,返回头图和正文图片的附件路径
+func (t *SaveWebNoteTool) saveImages(page *scraper.WebPage) (string, []string) {
+	log := logger.Get()
+
+	imgCfg := t.cfg.Note.Images
+	if imgCfg.AttachmentsFolder == "" {
+		imgCfg.AttachmentsFolder = "Attachments" // 默认附件文件夹
+	}
+	if imgCfg.MaxCount <= 0 {
+		imgCfg.MaxCount = 10 // 默认最多 10 张
+	}
+	if imgCfg.MaxSize <= 0 {
+		imgCfg.MaxSize = 5 << 20 // 默认单张 5MB
+	}
+
+	var leadImage string
+	var images []string
+	for _, img := range t.fetcher.DownloadImages(page, &imgCfg) {
+		attachmentPath, err := t.attachments.Save(imgCfg.AttachmentsFolder, img.Filename(), img.Data)
+		if err != nil {
+			log.Warn("保存图片附件失败", zap.String("image_url", img.URL), zap.Error(err))
+			continue
+		}
+
+		if img.URL == page.LeadImage {
+			leadImage = attachmentPath
+		} else {
+			images = append(images, attachmentPath)
+		}
+	}
+
+	return leadImage, images
+}
+
+// archivePage 存档原文: 内联为折叠区块或保存为同级文件,可选保存 HTML 快照
+// 存档失败只记录日志,不影响笔记保存
+func (t *SaveWebNoteTool) archivePage(ctx context.Context, page *scraper.WebPage, filename, folder string, meta *note.Meta) {
+	log := logger.Get()
+	archiveCfg := t.cfg.Note.Archive
+
+	markdown, err := scraper.ArticleMarkdown(page)
+	if err != nil {
+		log.Warn("提取原文失败,跳过存档", zap.String("url", page.URL), zap.Error(err))
+		return
+	}
+
+	if archiveCfg.Mode == "file" {
+		if t.obsidian == nil {
+			log.Warn("Obsidian 客户端未初始化,原文改为内联存档")
+		} else {
+			content := t.generator.GenerateArchive(page.Title, page.SourceURL(), markdown, page.FetchedAt)
+			archivePath, err := t.obsidian.SaveNote(ctx, content, filename+"-archive", folder)
+			if err != nil {
+				log.Warn("保存原文存档失败,改为内联存档", zap.String("url", page.URL), zap.Error(err))
+			} else {
+				meta.ArchivePath = archivePath
+			}
+		}
+	}
+	if meta.ArchivePath == "" {
+		meta.ArchiveInline = markdown
+	}
+
+	if !archiveCfg.HTMLSnapshot || page.RawHTML == "" {
+		return
+	}
+	if t.attachments == nil {
+		log.Warn("附件存储未初始化,跳过 HTML 快照")
+		return
+	}
+
+	snapshot, err := scraper.HTMLSnapshot(page)
+	if err != nil {
+		log.Warn("生成 HTML 快照失败", zap.String("url", page.URL), zap.Error(err))
+		return
+	}
+	// 文件名带内容哈希: 附件存储不覆盖已有文件,内容变化后需保存为新快照
+	snapshotName := filename + ".html"
+	if len(page.ContentHash) >= 8 {
+		snapshotName = fmt.Sprintf("%s-%s.html", filename, page.ContentHash[:8])
+	}
+	snapshotPath, err := t.attachments.Save(folder, snapshotName, []byte(snapshot))
+	if err != nil {
+		log.Warn("保存 HTML 快照失败", zap.String("url", page.URL), zap.Error(err))
+		return
+	}
+	meta.SnapshotPath = snapshotPath
+}
+
+// 每篇笔记默认最多生成的闪卡数
+const defaultMaxFlashcards = 10
+
+// noteLanguage 请求或配置中的笔记语言设置
+// 不是语言标签或 source 的值 (会写入 frontmatter 和提示词) 被忽略,依次回退到配置值和默认语言
+func (t *SaveWebNoteTool) noteLanguage(req SaveWebNoteRequest) string {
+	for _, setting := range []string{req.Language, t.cfg.Note.Language} {
+		if setting == "" {
+			continue
+		}
+		if lang.ValidSetting(setting) {
+			return setting
+		}
+		logger.Get().Warn("笔记语言无效,已忽略", zap.String("language", setting))
+	}
+	return ""
+}
+
+// noteOptions 影响笔记内容的请求选项摘要,与记录不同时重新生成内容未变化的页面
+func (t *SaveWebNoteTool) noteOptions(req SaveWebNoteRequest) string {
+	style := req.Style
+	if style == "" {
+		style = t.cfg.Model.Style
+	}
+	language := t.noteLanguage(req)
+	model := req.Model
+	if model == "" {
+		model = t.cfg.Model.ModelName
+	}
+	return fmt.Sprintf("style=%s;lang=%s;model=%s;flashcards=%d", style, language, model, t.flashcardCount())
+}
+
+// flashcardCount 闪卡数量上限,未启用闪卡时为 0
+func (t *SaveWebNoteTool) flashcardCount() int {
+	cfg := t.cfg.Note.Flashcards
+	switch {
+	case !cfg.Enabled:
+		return 0
+	case cfg.MaxCards > 0:
+		return cfg.MaxCards
+	default:
+		return defaultMaxFlashcards
+	}
+}
+
 // getFolder 获取保存文件夹
 func (t *SaveWebNoteTool) getFolder(customFolder string) string {
 	if customFolder != "" {
@@ -188,107 +525,150 @@ func (t *SaveWebNoteTool) getFolder(customFolder string) string {
 }
 
 // SaveWebNoteBatch 批量保存网页笔记 (并发处理)
-func (t *SaveWebNoteTool) SaveWebNoteBatch(ctx context.Context, urls []string, tags []string, folder string) []SaveWebNoteResponse {
+// opts 为所有 URL 共用的请求参数 (忽略其中的 URL 字段),返回结果与 urls 顺序一一对应
+// 规范化后相同或规范地址 (rel=canonical) 相同的 URL 只处理第一个,其余标记为重复而不生成笔记
+func (t *SaveWebNoteTool) SaveWebNoteBatch(ctx context.Context, urls []string, opts SaveWebNoteRequest) []SaveWebNoteResponse {
 	log := logger.Get()
 
+	responses := make([]SaveWebNoteResponse, len(urls))
+	duplicateOf := findDuplicates(urls)
+	unique := make([]string, 0, len(urls))
+	for i, url := range urls {
+		if _, dup := duplicateOf[i]; !dup {
+			unique = append(unique, url)
+		}
+	}
+
+	var fetchResults map[string]*scraper.FetchResult
 	if t.cachedFetcher == nil {
 		log.Warn("缓存抓取器未启用,批量处理将使用串行模式")
-		// 串行处理
-		responses := make([]SaveWebNoteResponse, len(urls))
-		for i, url := range urls {
-			req := SaveWebNoteRequest{
-				URL:    url,
-				Tags:   tags,
-				Folder: folder,
-			}
-			resp, err := t.SaveWebNote(ctx, req)
-			if err != nil {
-				resp = SaveWebNoteResponse{
-					Success: false,
-					Message: fmt.Sprintf("处理失败: %v", err),
-				}
-			}
-			responses[i] = resp
+		fetchResults = make(map[string]*scraper.FetchResult, len(unique))
+		for _, url := range unique {
+			t.report(url, StageFetching, 0)
+			page, status, err := t.fetchPage(url)
+			fetchResults[url] = &scraper.FetchResult{URL: url, Page: page, Status: status, Err: err}
+		}
+	} else {
+		// 并发抓取所有网页
+		log.Info("开始批量并发抓取", zap.Int("total_urls", len(unique)))
+		for _, url := range unique {
+			t.report(url, StageFetching, 0)
 		}
-		return responses
+		fetchResults = t.cachedFetcher.FetchBatch(ctx, unique)
 	}
 
-	// 并发抓取所有网页
-	log.Info("开始批量并发抓取", zap.Int("total_urls", len(urls)))
-	fetchResults := t.cachedFetcher.FetchBatch(ctx, urls)
-
 	// 处理抓取结果
-	responses := make([]SaveWebNoteResponse, 0, len(urls))
-	successCount := 0
+	successCount, budgetCount := 0, 0
+	sources := make(map[string]int, len(unique))
 
-	for _, result := range fetchResults {
-		if result.Err != nil {
-			responses = append(responses, SaveWebNoteResponse{
-				Success: false,
-				Message: fmt.Sprintf("抓取失败: %v", result.Err),
-			})
+	for i, url := range urls {
+		if _, dup := duplicateOf[i]; dup {
 			continue
 		}
 
-		// 对每个成功抓取的页面进行总结和保存
-		page := result.Page
-
-		// AI 总结
-		summary, err := t.summarizer.Summarize(ctx, page.Title, page.Content)
-		if err != nil {
-			log.Error("AI 总结失败", zap.String("url", page.URL), zap.Error(err))
-			responses = append(responses, SaveWebNoteResponse{
-				Success: false,
-				Message: fmt.Sprintf("AI 总结失败: %v", err),
-				Title:   page.Title,
-			})
+		result := fetchResults[url]
+		if result.Err != nil {
+			responses[i] = fetchFailure(result.Err)
+			t.report(url, StageDone, 0)
 			continue
 		}
 
-		// 生成 Markdown
-		markdown := t.generator.Generate(summary, page.URL)
-
-		// 保存到 Obsidian
-		filename := note.GenerateFilename(summary.Title)
-		saveFolder := t.getFolder(folder)
-		var filePath string
+		// 不同地址指向同一规范地址时视为重复
+		source := result.Page.SourceURL()
+		if j, ok := sources[source]; ok {
+			log.Info("规范地址重复,跳过", zap.String("url", url), zap.String("source", source))
+			duplicateOf[i] = j
+			t.report(url, StageDone, 0)
+			continue
+		}
+		sources[source] = i
+
+		// 预算用尽后不再总结剩余页面,已抓取的页面缓存供下次运行使用
+		if t.summarizer.BudgetExceeded() {
+			responses[i] = budgetSkipped()
+			responses[i].Title = result.Page.Title
+			budgetCount++
+			t.report(url, StageDone, 0)
+			continue
+		}
 
-		if t.obsidian != nil {
-			actualPath, err := t.obsidian.SaveNote(ctx, markdown, filename, saveFolder)
-			if err != nil {
-				log.Error("保存到 Obsidian 失败", zap.String("url", page.URL), zap.Error(err))
-				responses = append(responses, SaveWebNoteResponse{
-					Success: false,
-					Message: fmt.Sprintf("保存失败: %v", err),
-					Title:   summary.Title,
-					Content: markdown,
-				})
-				continue
+		// 对每个成功抓取的页面进行总结和保存
+		req := opts
+		req.URL = url
+		resp, err := t.processPage(ctx, result.Page, result.Status, req)
+		if resp.Status == StatusBudget {
+			budgetCount++
+		} else if err == nil {
+			successCount++
+			if !resp.Skipped {
+				resp.NoteCount = 1
 			}
-			filePath = actualPath
-		} else {
-			filePath = fmt.Sprintf("%s/%s.md", saveFolder, filename)
 		}
-
-		successCount++
-		responses = append(responses, SaveWebNoteResponse{
-			Success:  true,
-			Message:  "笔记保存成功",
-			Title:    summary.Title,
-			FilePath: filePath,
-			Content:  markdown,
-		})
+		responses[i] = resp
+		t.report(url, StageDone, 0)
 	}
+	fillDuplicates(responses, duplicateOf)
 
+	usage := t.summarizer.TotalUsage()
 	log.Info("批量处理完成",
 		zap.Int("total", len(urls)),
 		zap.Int("success", successCount),
-		zap.Int("failed", len(urls)-successCount),
+		zap.Int("failed", len(urls)-len(duplicateOf)-successCount-budgetCount),
+		zap.Int("duplicates", len(duplicateOf)),
+		zap.Int("budget_skipped", budgetCount),
+		zap.Int("total_tokens", usage.TotalTokens()),
+		zap.Float64("cost", usage.Cost),
 	)
 
 	return responses
 }
 
+// budgetSkipped 预算用尽时的响应
+func budgetSkipped() SaveWebNoteResponse {
+	return SaveWebNoteResponse{
+		Success: false,
+		Message: "预算已用尽,跳过",
+		Status:  StatusBudget,
+		Skipped: true,
+	}
+}
+
+// findDuplicates 找出规范化后重复的 URL
+// 返回 重复项下标 -> 首次出现的下标
+func findDuplicates(urls []string) map[int]int {
+	first := make(map[string]int, len(urls))
+	duplicateOf := make(map[int]int)
+	for i, url := range urls {
+		key := urlnorm.Normalize(url)
+		if j, ok := first[key]; ok {
+			duplicateOf[i] = j
+			continue
+		}
+		first[key] = i
+	}
+	return duplicateOf
+}
+
+// fillDuplicates 为重复的 URL 填充响应,指向首次出现的处理结果
+func fillDuplicates(responses []SaveWebNoteResponse, duplicateOf map[int]int) {
+	for i, j := range duplicateOf {
+		original := responses[j]
+		responses[i] = SaveWebNoteResponse{
+			Success:  original.Success,
+			Message:  fmt.Sprintf("重复的 URL,与第 %d 个相同", j+1),
+			Title:    original.Title,
+			FilePath: original.FilePath,
+			Status:   StatusDuplicate,
+			Skipped:  true,
+		}
+	}
+}
+
+// Styles 可用的总结风格
+func (t *SaveWebNoteTool) Styles() []string {
+	return t.summarizer.Styles()
+}
+
 // GetCacheStats 获取缓存统计信息
 func (t *SaveWebNoteTool) GetCacheStats() map[string]interface{} {
 	if t.cachedFetcher == nil {
@@ -297,11 +677,20 @@ func (t *SaveWebNoteTool) GetCacheStats() map[string]interface{} {
 		}
 	}
 
+	stats := t.cachedFetcher.CacheStats()
 	return map[string]interface{}{
-		"enabled":     true,
-		"cache_size":  t.cachedFetcher.GetCacheSize(),
-		"cache_ttl":   t.cfg.Scraper.CacheTTL.String(),
-		"max_concurrency": t.cfg.Scraper.MaxConcurrency,
+		"enabled":            true,
+		"cache_size":         stats.Entries,
+		"cache_bytes":        stats.Bytes,
+		"max_entries":        stats.MaxEntries,
+		"max_bytes":          stats.MaxBytes,
+		"hits":               stats.Hits,
+		"misses":             stats.Misses,
+		"evictions":          stats.Evictions,
+		"expired":            stats.Expired,
+		"summary_cache_size": t.summaryCacheSize(),
+		"cache_ttl":          t.cfg.Scraper.CacheTTL.String(),
+		"max_concurrency":    t.cfg.Scraper.MaxConcurrency,
 	}
 }
 
@@ -310,4 +699,29 @@ func (t *SaveWebNoteTool) ClearCache() {
 	if t.cachedFetcher != nil {
 		t.cachedFetcher.ClearCache()
 	}
+	if t.summaryCache != nil {
+		if err := t.summaryCache.Clear(); err != nil {
+			logger.Get().Warn("清空总结缓存失败", zap.Error(err))
+		}
+	}
+}
+
+// summaryCacheSize 总结缓存条目数
+func (t *SaveWebNoteTool) summaryCacheSize() int {
+	if t.summaryCache == nil {
+		return 0
+	}
+	return t.summaryCache.Size()
+}
+
+// Close 释放资源: 停止缓存清理任务并关闭 Obsidian MCP 客户端
+func (t *SaveWebNoteTool) Close() {
+	if t.cachedFetcher != nil {
+		t.cachedFetcher.Close()
+	}
+	if t.obsidian != nil {
+		if err := t.obsidian.Close(); err != nil {
+			logger.Get().Debug("关闭 Obsidian MCP 客户端失败", zap.Error(err))
+		}
+	}
 }
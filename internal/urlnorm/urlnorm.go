@@ -0,0 +1,91 @@
+// Package urlnorm 提供 URL 规范化,用于缓存键、去重和笔记来源
+package urlnorm
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams 需要移除的跟踪参数 (utm_* 按前缀匹配)
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"spm":     true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+}
+
+// defaultPorts 各协议的默认端口
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Normalize 规范化 URL
+//   - 协议和主机名转为小写,去掉默认端口
+//   - 去掉片段 (#...)
+//   - 去掉路径末尾的斜杠 (包括根路径 "/")
+//   - 移除 utm_*、fbclid、spm 等跟踪参数,其余参数按名称排序
+//
+// 无法解析或不是绝对地址时返回去掉首尾空白的原字符串
+func Normalize(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); port != "" && defaultPorts[u.Scheme] == port {
+		u.Host = u.Hostname()
+		if strings.Contains(u.Host, ":") {
+			u.Host = "[" + u.Host + "]" // IPv6
+		}
+	}
+
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+
+	u.RawQuery = stripTracking(u.RawQuery)
+	u.ForceQuery = false
+
+	return u.String()
+}
+
+// Equal 判断两个 URL 规范化后是否相同
+func Equal(a, b string) bool {
+	return Normalize(a) == Normalize(b)
+}
+
+// IsTrackingParam 是否为跟踪参数
+func IsTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "utm_") || trackingParams[name]
+}
+
+// stripTracking 移除跟踪参数并排序
+// 查询串无法解析时原样返回,避免破坏地址
+func stripTracking(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	for name := range values {
+		if IsTrackingParam(name) {
+			delete(values, name)
+		}
+	}
+	return values.Encode()
+}
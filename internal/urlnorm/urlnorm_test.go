@@ -0,0 +1,47 @@
+package urlnorm
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"大小写", "HTTPS://Example.COM/Path", "https://example.com/Path"},
+		{"默认端口 https", "https://example.com:443/a", "https://example.com/a"},
+		{"默认端口 http", "http://example.com:80/a", "http://example.com/a"},
+		{"非默认端口", "https://example.com:8443/a", "https://example.com:8443/a"},
+		{"片段", "https://example.com/a#section", "https://example.com/a"},
+		{"尾部斜杠", "https://example.com/a/", "https://example.com/a"},
+		{"根路径", "https://example.com/", "https://example.com"},
+		{"utm 参数", "https://example.com/a?utm_source=x&utm_medium=y", "https://example.com/a"},
+		{"fbclid 和 spm", "https://example.com/a?id=1&fbclid=abc&spm=a2c", "https://example.com/a?id=1"},
+		{"参数排序", "https://example.com/a?b=2&a=1", "https://example.com/a?a=1&b=2"},
+		{"空查询", "https://example.com/a?", "https://example.com/a"},
+		{"首尾空白", "  https://example.com/a  ", "https://example.com/a"},
+		{"相对地址", "/a/b/", "/a/b/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	variants := []string{
+		"https://x.com/a",
+		"https://x.com/a/",
+		"https://X.com/a#section",
+		"https://x.com/a?utm_source=newsletter",
+	}
+	for _, v := range variants {
+		if !Equal(variants[0], v) {
+			t.Errorf("Equal(%q, %q) = false, want true", variants[0], v)
+		}
+	}
+}
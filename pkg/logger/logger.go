@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"io"
 	"os"
 
 	"go.uber.org/zap"
@@ -11,6 +12,15 @@ import (
 
 var log *zap.Logger
 
+// console 控制台日志的输出
+var console io.Writer = os.Stdout
+
+// SetConsole 设置控制台日志的输出 (默认为标准输出),需在 Init 之前调用
+// 命令行显示实时进度时,用于在输出日志前后清除和重绘状态行
+func SetConsole(w io.Writer) {
+	console = w
+}
+
 // Init 初始化日志
 func Init(cfg *config.Config) error {
 	// 配置编码器
@@ -59,7 +69,7 @@ func Init(cfg *config.Config) error {
 		}
 		writeSyncer = zapcore.AddSync(file)
 	} else {
-		writeSyncer = zapcore.AddSync(os.Stdout)
+		writeSyncer = zapcore.AddSync(console)
 	}
 
 	// 创建核心